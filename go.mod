@@ -11,7 +11,7 @@ require (
 	github.com/containerd/containerd v1.3.0 // indirect
 	github.com/docker/distribution v2.7.1+incompatible // indirect
 	github.com/docker/docker v17.12.0-ce-rc1.0.20191007211215-3e077fc8667a+incompatible
-	github.com/docker/go-connections v0.4.0 // indirect
+	github.com/docker/go-connections v0.4.0
 	github.com/docker/go-units v0.4.0 // indirect
 	github.com/gogo/protobuf v1.3.0 // indirect
 	github.com/google/go-github/v32 v32.0.0
@@ -30,6 +30,7 @@ require (
 	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli v1.21.0
 	github.com/zalando/go-keyring v0.0.0-20190913082157-62750a1ff80d
+	golang.org/x/net v0.0.0-20191126235420-ef20fe5d7933
 	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
 	golang.org/x/time v0.0.0-20191023065245-6d3f0bb11be5 // indirect
 	google.golang.org/appengine v1.6.5 // indirect
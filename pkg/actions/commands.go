@@ -72,9 +72,11 @@ func Commands() {
 					Usage: "Create a project on disk",
 
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "url, u", Usage: "URL of project to download", Required: true},
+						cli.StringFlag{Name: "url, u", Usage: "URL of project to download, required if 'template' is not given", Required: false},
+						cli.StringFlag{Name: "template", Usage: "Label or URL of a template known by the connection, required if 'url' is not given", Required: false},
 						cli.StringFlag{Name: "path, p", Usage: "The path at which to create the new project", Required: true},
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of PFE which will be used to validate the project", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of PFE which will be used to validate the project, defaults to the current connection", Required: false},
+						cli.BoolFlag{Name: "bind", Usage: "Bind the project to the connection once it has been created and validated", Required: false},
 						cli.StringFlag{Name: "username", Usage: "Username for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "password", Usage: "Password for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "personalAccessToken", Usage: "PersonalAccessToken authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
@@ -92,7 +94,7 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "type, t", Usage: "Known build type of project", Required: false},
 						cli.StringFlag{Name: "path, p", Usage: "The path at which to create the new project", Required: true},
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id for the project", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id for the project, defaults to the current connection", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectValidate(c)
@@ -107,7 +109,7 @@ func Commands() {
 						cli.StringFlag{Name: "language, l", Usage: "The project language", Required: true},
 						cli.StringFlag{Name: "type, t", Usage: "The type of the project", Required: true},
 						cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id for the project", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id for the project, defaults to the current connection", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectBind(c)
@@ -118,8 +120,16 @@ func Commands() {
 					Name:  "remove",
 					Usage: "Remove a project from codewind",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
+						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
 						cli.BoolFlag{Name: "delete, d", Usage: "delete local project files"},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the projects to select, defaults to the current connection", Required: false},
+						cli.BoolFlag{Name: "all", Usage: "Remove every project on the connection"},
+						cli.StringFlag{Name: "name-pattern", Usage: "Remove the projects whose names match a pattern, e.g. 'node-*'", Required: false},
+						cli.StringFlag{Name: "language", Usage: "Remove the projects with this language", Required: false},
+						cli.StringFlag{Name: "status", Usage: "Remove the projects with this app status, e.g. started or stopped", Required: false},
+						cli.IntFlag{Name: "concurrency", Value: 4, Usage: "The number of projects to remove at once"},
+						cli.BoolFlag{Name: "yes, y", Usage: "Remove the selected projects without asking first"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectRemove(c)
@@ -130,9 +140,16 @@ func Commands() {
 					Name:  "sync",
 					Usage: "Synchronize a project to codewind for building and running",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "path, p", Usage: "the path to the project", Required: true},
-						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
-						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds", Required: true},
+						cli.StringFlag{Name: "path, p", Usage: "the path to the project", Required: false},
+						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the projects to select, defaults to the current connection", Required: false},
+						cli.BoolFlag{Name: "all", Usage: "Sync every project on the connection"},
+						cli.StringFlag{Name: "name-pattern", Usage: "Sync the projects whose names match a pattern, e.g. 'node-*'", Required: false},
+						cli.StringFlag{Name: "language", Usage: "Sync the projects with this language", Required: false},
+						cli.StringFlag{Name: "status", Usage: "Sync the projects with this app status, e.g. started or stopped", Required: false},
+						cli.IntFlag{Name: "concurrency", Value: 4, Usage: "The number of projects to sync at once"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectSync(c)
@@ -144,7 +161,9 @@ func Commands() {
 					Aliases: []string{"ls"},
 					Usage:   "List projects",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the remote deployment to use, defaults to the current connection", Required: false},
+						cli.StringFlag{Name: "fields", Usage: "Comma separated project fields to print, e.g. name,appStatus,ports.exposedPort", Required: false},
+						cli.StringSliceFlag{Name: "filter", Usage: "Only list projects with a field matching a pattern, e.g. language=node*. Use != to exclude projects. May be repeated"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectList(c)
@@ -157,7 +176,8 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "id,i", Usage: "Project ID", Required: false},
 						cli.StringFlag{Name: "name,n", Usage: "Project name", Required: false},
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the remote deployment to use, defaults to the current connection", Required: false},
+						cli.StringFlag{Name: "fields", Usage: "Comma separated project fields to print, e.g. name,appStatus,ports.exposedPort", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectGet(c)
@@ -166,17 +186,177 @@ func Commands() {
 				},
 				{
 					Name:  "restart",
-					Usage: "Restart a single project, requires 'id' and 'startMode'",
+					Usage: "Restart a project, or the projects matching --all, --name-pattern, --language or --status, requires 'startMode'",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "id,i", Usage: "Project ID", Required: true},
+						cli.StringFlag{Name: "id,i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
 						cli.StringFlag{Name: "startmode, s", Usage: "Start Mode of the project; can be run, debug, or debugNoInit", Required: true},
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the remote deployment to use, defaults to the current connection", Required: false},
+						cli.BoolFlag{Name: "all", Usage: "Restart every project on the connection"},
+						cli.StringFlag{Name: "name-pattern", Usage: "Restart the projects whose names match a pattern, e.g. 'node-*'", Required: false},
+						cli.StringFlag{Name: "language", Usage: "Restart the projects with this language", Required: false},
+						cli.StringFlag{Name: "status", Usage: "Restart the projects with this app status, e.g. started or stopped", Required: false},
+						cli.IntFlag{Name: "concurrency", Value: 4, Usage: "The number of projects to restart at once"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectRestart(c)
 						return nil
 					},
 				},
+				{
+					Name:  "reindex",
+					Usage: "Rebuild the index of which connection each project is bound to",
+					Action: func(c *cli.Context) error {
+						ProjectReindex(c)
+						return nil
+					},
+				},
+				{
+					Name:  "wait",
+					Usage: "Wait for a project to be started, stopped or built",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+						cli.StringFlag{Name: "for", Value: "started", Usage: "The state to wait for: started, stopped or built", Required: false},
+						cli.StringFlag{Name: "timeout", Value: "10m", Usage: "How long to wait before giving up (e.g. 90s, 10m)", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectWait(c)
+						return nil
+					},
+				},
+				{
+					Name:  "logs",
+					Usage: "Print the build or app log of a project",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+						cli.StringFlag{Name: "type, t", Value: "app", Usage: "The log to print, app or build", Required: false},
+						cli.BoolFlag{Name: "follow, f", Usage: "Keep printing new log lines until interrupted"},
+						cli.StringFlag{Name: "since", Usage: "Only print lines since a timestamp (e.g. 2020-02-01T10:00:00Z) or a duration (e.g. 10m)", Required: false},
+						cli.StringFlag{Name: "tail", Value: "all", Usage: "Only print this number of lines from the end of the log", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectLogs(c)
+						return nil
+					},
+				},
+				{
+					Name:  "debug",
+					Usage: "Restart a project in debug mode if needed and print the local address to attach a debugger to",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+						cli.StringFlag{Name: "timeout", Value: "5m", Usage: "How long to wait for the project to start in debug mode (e.g. 90s, 5m)", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectDebug(c)
+						return nil
+					},
+				},
+				{
+					Name:  "move",
+					Usage: "Move a project to another connection, recreating its links where the target projects exist",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+						cli.StringFlag{Name: "to", Usage: "The connection id of the deployment to move the project to", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectMove(c)
+						return nil
+					},
+				},
+				{
+					Name:  "export",
+					Usage: "Export a project, its settings, links and bind metadata to a bundle file",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+						cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+						cli.StringFlag{Name: "output, o", Usage: "The path of the bundle file to write, defaults to <project name>.cwbundle.tar.gz", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectExport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "import",
+					Usage: "Recreate a project from a bundle file, bind it and restore its links",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "bundle, b", Usage: "The path of the bundle file to import", Required: true},
+						cli.StringFlag{Name: "path, p", Usage: "The empty directory to extract the project to, defaults to the project name", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the remote deployment to use, defaults to the current connection", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectImport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "settings",
+					Usage: "Manage the .cw-settings file of a project",
+					Subcommands: []cli.Command{
+						{
+							Name:      "get",
+							Aliases:   []string{"g"},
+							Usage:     "Get the value of a setting, or all settings if no setting is given",
+							ArgsUsage: "[setting]",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsGet(c)
+								return nil
+							},
+						},
+						{
+							Name:      "set",
+							Aliases:   []string{"s"},
+							Usage:     "Set the value of a setting, refusing values that are invalid for the project type",
+							ArgsUsage: "<setting> <value>",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+								cli.StringFlag{Name: "type, t", Usage: "The type of the project, detected from the project files if not given", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsSet(c)
+								return nil
+							},
+						},
+						{
+							Name:      "unset",
+							Aliases:   []string{"u"},
+							Usage:     "Remove a setting",
+							ArgsUsage: "<setting>",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsUnset(c)
+								return nil
+							},
+						},
+						{
+							Name:    "validate",
+							Aliases: []string{"v"},
+							Usage:   "Validate the settings against the schema for the project type",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+								cli.StringFlag{Name: "type, t", Usage: "The type of the project, detected from the project files if not given", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsValidate(c)
+								return nil
+							},
+						},
+					},
+				},
 				{
 					Name:  "link",
 					Usage: "Manage project links",
@@ -186,7 +366,9 @@ func Commands() {
 							Aliases: []string{"ls"},
 							Usage:   "Lists all the links for a project",
 							Flags: []cli.Flag{
-								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+								cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+								cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
 							},
 							Action: func(c *cli.Context) error {
 								ProjectLinkList(c)
@@ -198,8 +380,11 @@ func Commands() {
 							Aliases: []string{"c"},
 							Usage:   "Creates a new link for a project",
 							Flags: []cli.Flag{
-								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
-								cli.StringFlag{Name: "targetID, t", Usage: "The Project ID of the project to add a link to", Required: true},
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+								cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+								cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
+								cli.StringFlag{Name: "targetID, t", Usage: "The Project ID of the project to add a link to", Required: false},
+								cli.StringFlag{Name: "targetName", Usage: "The name of the project to add a link to, instead of its Project ID", Required: false},
 								cli.StringFlag{Name: "env, e", Usage: "Environment variable name", Required: true},
 							},
 							Action: func(c *cli.Context) error {
@@ -211,7 +396,9 @@ func Commands() {
 							Aliases: []string{"r"},
 							Usage:   "Renames the environment variable name for a link",
 							Flags: []cli.Flag{
-								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+								cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+								cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
 								cli.StringFlag{Name: "env, e", Usage: "Environment variable name", Required: true},
 								cli.StringFlag{Name: "newEnv, n", Usage: "New environment variable name", Required: true},
 							},
@@ -224,7 +411,9 @@ func Commands() {
 							Aliases: []string{"rm"},
 							Usage:   "Removes a project link",
 							Flags: []cli.Flag{
-								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: false},
+								cli.StringFlag{Name: "name", Usage: "Project name, instead of the project ID", Required: false},
+								cli.StringFlag{Name: "conid", Usage: "The connection id to look up the project name on, defaults to every connection", Required: false},
 								cli.StringFlag{Name: "env, e", Usage: "Environment variable name", Required: true},
 							},
 							Action: func(c *cli.Context) error {
@@ -232,6 +421,18 @@ func Commands() {
 								return nil
 							},
 						},
+						{
+							Name:  "graph",
+							Usage: "Prints the links between all of the projects on a connection",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the projects, defaults to the current connection", Required: false},
+								cli.StringFlag{Name: "format", Value: "dot", Usage: "Output format, dot or json", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLinkGraph(c)
+								return nil
+							},
+						},
 					},
 				},
 			},
@@ -306,7 +507,7 @@ func Commands() {
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "conid",
-					Usage: "ConnectionID to check",
+					Usage: "ConnectionID to check, defaults to the current connection",
 				},
 			},
 			Action: func(c *cli.Context) error {
@@ -403,8 +604,7 @@ func Commands() {
 						},
 						cli.StringFlag{
 							Name:     "conid",
-							Value:    "local",
-							Usage:    "Connection ID",
+							Usage:    "Connection ID, defaults to the current connection",
 							Required: false,
 						},
 					},
@@ -419,8 +619,7 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{
 							Name:     "conid",
-							Value:    "local",
-							Usage:    "Connection ID",
+							Usage:    "Connection ID, defaults to the current connection",
 							Required: false,
 						},
 					},
@@ -440,8 +639,7 @@ func Commands() {
 							Flags: []cli.Flag{
 								cli.StringFlag{
 									Name:     "conid",
-									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, defaults to the current connection",
 									Required: false,
 								},
 							},
@@ -470,8 +668,7 @@ func Commands() {
 								},
 								cli.StringFlag{
 									Name:     "conid",
-									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, defaults to the current connection",
 									Required: false,
 								},
 								cli.StringFlag{
@@ -506,8 +703,7 @@ func Commands() {
 								},
 								cli.StringFlag{
 									Name:     "conid",
-									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, defaults to the current connection",
 									Required: false,
 								},
 							},
@@ -522,8 +718,7 @@ func Commands() {
 							Flags: []cli.Flag{
 								cli.StringFlag{
 									Name:     "conid",
-									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, defaults to the current connection",
 									Required: false,
 								},
 							},
@@ -538,8 +733,7 @@ func Commands() {
 							Flags: []cli.Flag{
 								cli.StringFlag{
 									Name:     "conid",
-									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, defaults to the current connection",
 									Required: false,
 								},
 							},
@@ -552,6 +746,50 @@ func Commands() {
 				},
 			},
 		},
+		{
+			Name:    "extensions",
+			Aliases: []string{"ext"},
+			Usage:   "Inspect the project extensions installed on a connection",
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the installed extensions",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ListExtensions(c)
+						return nil
+					},
+				},
+				{
+					Name:      "show",
+					Usage:     "Show the detection file, style and commands of the extension for a project type",
+					ArgsUsage: "<projectType>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ShowExtension(c)
+						return nil
+					},
+				},
+				{
+					Name:  "test",
+					Usage: "Report which extension would be used for a local project, and why",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+						cli.StringFlag{Name: "type, t", Usage: "The project type hint, as given to project validate", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						TestExtensions(c)
+						return nil
+					},
+				},
+			},
+		},
 
 		//  Security //
 		{
@@ -569,7 +807,7 @@ func Commands() {
 						cli.StringFlag{Name: "username,u", Usage: "Account Username", Required: true},
 						cli.StringFlag{Name: "password,p", Usage: "Account Password", Required: false},
 						cli.StringFlag{Name: "client,c", Usage: "Client", Required: false},
-						cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection unless the host, realm and client are given", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						SecurityTokenGet(c)
@@ -580,7 +818,7 @@ func Commands() {
 					Aliases: []string{"r"},
 					Usage:   "Obtain an access token using a refresh_token",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						SecurityTokenRefresh(c)
@@ -599,7 +837,7 @@ func Commands() {
 					Aliases: []string{"u"},
 					Usage:   "Add new or update existing Codewind credentials in the keyring",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Usage: "Connection ID (see the connections cmd)", Required: true},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID (see the connections cmd), defaults to the current connection", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
 						cli.StringFlag{Name: "password,p", Usage: "New password", Required: true},
 					},
@@ -612,7 +850,7 @@ func Commands() {
 					Aliases: []string{"v"},
 					Usage:   "Checks if Codewind credentials exist in the keyring",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid,d", Usage: "Keycloak login ID", Required: true},
+						cli.StringFlag{Name: "conid,d", Usage: "Keycloak login ID, defaults to the current connection", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
 					},
 					Action: func(c *cli.Context) error {
@@ -806,9 +1044,18 @@ func Commands() {
 					Aliases: []string{"a"},
 					Usage:   "Add a new connection to the configuration file",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
-						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
-						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "label", Usage: "A displayable name"},
+						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper"},
+						cli.StringFlag{Name: "username,u", Usage: "Username"},
+						cli.StringFlag{Name: "cacert", Usage: "Path to a PEM encoded CA bundle to trust for this connection"},
+						cli.StringFlag{Name: "fingerprint", Usage: "SHA-256 fingerprint of the certificate this connection must present"},
+						cli.StringFlag{Name: "http-proxy", Usage: "Proxy for http requests to this connection"},
+						cli.StringFlag{Name: "https-proxy", Usage: "Proxy for https requests to this connection"},
+						cli.StringFlag{Name: "socks5-proxy", Usage: "SOCKS5 proxy for requests to this connection that have no other proxy"},
+						cli.StringFlag{Name: "no-proxy", Usage: "Comma separated hosts and domains this connection requests directly"},
+						cli.BoolFlag{Name: "from-kube", Usage: "Add a connection for each Codewind deployment in Kubernetes that does not have one"},
+						cli.StringFlag{Name: "namespace,n", Usage: "With --from-kube, only add deployments in this namespace"},
+						cli.StringFlag{Name: "workspace,w", Usage: "With --from-kube, only add the deployment with this workspace ID"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionAddToList(c)
@@ -824,6 +1071,12 @@ func Commands() {
 						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
 						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
 						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "cacert", Usage: "Path to a PEM encoded CA bundle to trust for this connection"},
+						cli.StringFlag{Name: "fingerprint", Usage: "SHA-256 fingerprint of the certificate this connection must present"},
+						cli.StringFlag{Name: "http-proxy", Usage: "Proxy for http requests to this connection"},
+						cli.StringFlag{Name: "https-proxy", Usage: "Proxy for https requests to this connection"},
+						cli.StringFlag{Name: "socks5-proxy", Usage: "SOCKS5 proxy for requests to this connection that have no other proxy"},
+						cli.StringFlag{Name: "no-proxy", Usage: "Comma separated hosts and domains this connection requests directly"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionUpdate(c)
@@ -863,6 +1116,50 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:      "use",
+					Usage:     "Set the current connection, used by commands that are not given --conid",
+					ArgsUsage: "<connection id>",
+					Action: func(c *cli.Context) error {
+						ConnectionUse(c)
+						return nil
+					},
+				},
+				{
+					Name:  "status",
+					Usage: "Check the health of every connection",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "timeout", Value: "10s", Usage: "How long to wait for each request (e.g. 5s, 1m)", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionStatus(c)
+						return nil
+					},
+				},
+				{
+					Name:  "export",
+					Usage: "Export connections to a shareable file that holds no secrets",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "ids", Usage: "Comma separated IDs of the connections to export, defaults to every remote connection", Required: false},
+						cli.StringFlag{Name: "file, f", Usage: "File to write the connections to, defaults to standard output", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionExport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "import",
+					Usage: "Add the connections in an exported file to the connections list",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file, f", Usage: "File of exported connections", Required: true},
+						cli.StringFlag{Name: "on-conflict", Value: "skip", Usage: "What to do with a connection whose label or URL is in use: skip, overwrite or rename", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionImport(c)
+						return nil
+					},
+				},
 				{
 					Name:  "reset",
 					Usage: "Resets the connections list",
@@ -871,6 +1168,17 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:  "migrate",
+					Usage: "Migrate the connections config to the schema version used by this cwctl",
+					Flags: []cli.Flag{
+						cli.BoolFlag{Name: "dry-run", Usage: "Show the connections config before and after the migration without changing it"},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionMigrate(c)
+						return nil
+					},
+				},
 			},
 		},
 		{
@@ -896,6 +1204,8 @@ func Commands() {
 			Usage:   "Upgrade projects",
 			Flags: []cli.Flag{
 				cli.StringFlag{Name: "workspace, ws", Usage: "the workspace directory to upgrade, location of projects", Required: true},
+				cli.StringFlag{Name: "conid", Usage: "The connection id of the deployment to bind the projects to, defaults to the current connection", Required: false},
+				cli.BoolFlag{Name: "dry-run", Usage: "Report the projects that would be upgraded without changing anything"},
 			},
 			Action: func(c *cli.Context) error {
 				UpgradeProjects(c)
@@ -909,8 +1219,7 @@ func Commands() {
 			Flags: []cli.Flag{
 				cli.StringFlag{
 					Name:  "conid",
-					Usage: "ConnectionID to check",
-					Value: "local",
+					Usage: "ConnectionID to check, defaults to the current connection",
 				},
 			},
 			Action: func(c *cli.Context) error {
@@ -928,7 +1237,7 @@ func Commands() {
 					Aliases: []string{"a"},
 					Usage:   "Add a new docker registry secret and return the updated list of secrets",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "Connection ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
 						cli.StringFlag{Name: "address,a", Usage: "Registry address", Required: true},
 						cli.StringFlag{Name: "username,u", Usage: "Registry username", Required: true},
 						cli.StringFlag{Name: "password,p", Usage: "Registry password", Required: true},
@@ -944,7 +1253,7 @@ func Commands() {
 					Aliases: []string{"ls"},
 					Usage:   "List the docker secrets (registries and usernames)",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "Connection ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						GetRegistrySecrets(c)
@@ -956,7 +1265,7 @@ func Commands() {
 					Aliases: []string{"rm"},
 					Usage:   "Remove a docker registry secret and return the updated list of secrets",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "Connection ID", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, defaults to the current connection", Required: false},
 						cli.StringFlag{Name: "address,a", Usage: "Registry address", Required: true},
 					},
 					Action: func(c *cli.Context) error {
@@ -971,7 +1280,7 @@ func Commands() {
 			Aliases: []string{"v"},
 			Usage:   "Get versions of deployed Codewind containers",
 			Flags: []cli.Flag{
-				cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection ID", Required: false},
+				cli.StringFlag{Name: "conid", Usage: "The connection ID, defaults to the current connection", Required: false},
 				cli.BoolFlag{Name: "all, a", Usage: "Get the codewind container versions for all connections", Required: false},
 			},
 			Action: func(c *cli.Context) error {
@@ -989,7 +1298,7 @@ func Commands() {
 					Name:  "collect",
 					Usage: "Gathers logs and project files to aid diagnosis of Codewind errors",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "Triggers diagnostics collection for the `remote` codewind instance (_must_ have currently configured Kubectl connection!)", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Triggers diagnostics collection for the `remote` codewind instance (_must_ have currently configured Kubectl connection!), defaults to the current connection", Required: false},
 						cli.StringFlag{Name: "eclipseWorkspaceDir, e", Usage: "The location of your Eclipse workspace `directory` if using the Eclipse IDE", Required: false},
 						cli.StringFlag{Name: "intellijLogsDir, i", Usage: "The location of your IntelliJ logs `directory` if using the IntelliJ IDE", Required: false},
 						cli.BoolFlag{Name: "all, a", Usage: "Collects diagnostics for all defined connections, remote and local", Required: false},
@@ -13,10 +13,13 @@ package actions
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/security"
@@ -26,6 +29,21 @@ import (
 
 // ConnectionAddToList : Add new connection to the connections config file and returns the ID of the added entry
 func ConnectionAddToList(c *cli.Context) {
+	if c.Bool("from-kube") {
+		ConnectionAddFromKube(c)
+		return
+	}
+	missing := []string{}
+	for _, flag := range []string{"label", "url", "username"} {
+		if strings.TrimSpace(c.String(flag)) == "" {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("Required flags %q not set, or use --from-kube", strings.Join(missing, ", "))
+		HandleConnectionError(&connections.ConError{Op: "con_missing_flags", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
 	connection, conErr := connections.AddConnectionToList(http.DefaultClient, c)
 	if conErr != nil {
 		HandleConnectionError(conErr)
@@ -163,3 +181,153 @@ func ConnectionResetList(c *cli.Context) {
 	}
 	os.Exit(0)
 }
+
+// ConnectionExport : Writes the connections with the given IDs, or all remote connections, to a shareable file without their secrets
+func ConnectionExport(c *cli.Context) {
+	ids := []string{}
+	for _, id := range strings.Split(c.String("ids"), ",") {
+		if strings.TrimSpace(id) != "" {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+	}
+	export, conErr := connections.ExportConnections(ids)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	body, _ := json.MarshalIndent(export, "", "\t")
+
+	file := strings.TrimSpace(c.String("file"))
+	if file == "" {
+		fmt.Println(string(body))
+		os.Exit(0)
+	}
+	err := ioutil.WriteFile(file, body, 0644)
+	if err != nil {
+		HandleConnectionError(&connections.ConError{Op: "con_write", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+	if printAsJSON {
+		response, _ := json.Marshal(connections.Result{Status: "OK", StatusMessage: "Connections exported to " + file})
+		fmt.Println(string(response))
+	} else {
+		logr.Printf("%v connections exported to %v", len(export.Connections), file)
+	}
+	os.Exit(0)
+}
+
+// ConnectionImport : Adds the connections in an export file to the connections list
+func ConnectionImport(c *cli.Context) {
+	body, err := ioutil.ReadFile(strings.TrimSpace(c.String("file")))
+	if err != nil {
+		HandleConnectionError(&connections.ConError{Op: "con_load", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+	export, conErr := connections.ParseConnectionsExport(body)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	policy := strings.TrimSpace(strings.ToLower(c.String("on-conflict")))
+	results, conErr := connections.ImportConnections(http.DefaultClient, export, policy)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+
+	printImportResults(results)
+}
+
+// printImportResults : Prints the result of adding each imported connection, and exits with status 1 if any failed
+func printImportResults(results []connections.ImportResult) {
+	if printAsJSON {
+		response, _ := json.Marshal(results)
+		fmt.Println(string(response))
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "CONNECTION ID\tLABEL\tURL\tSTATUS\tDETAIL")
+		for _, result := range results {
+			fmt.Fprintln(w, result.ID+"\t"+result.Label+"\t"+result.URL+"\t"+result.Status+"\t"+result.Detail)
+		}
+		w.Flush()
+	}
+	for _, result := range results {
+		if result.Status == "failed" {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// ConnectionUse : Makes a connection the current connection, used by commands that are not given --conid
+func ConnectionUse(c *cli.Context) {
+	connectionID := strings.TrimSpace(c.Args().Get(0))
+	if connectionID == "" {
+		err := errors.New("A connection ID must be given, e.g. cwctl connections use local")
+		HandleConnectionError(&connections.ConError{Op: "con_not_found", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+	connection, conErr := connections.SetDefaultConnection(connectionID)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	if printAsJSON {
+		type Result struct {
+			Status        string `json:"status"`
+			StatusMessage string `json:"status_message"`
+			ConID         string `json:"id"`
+		}
+		response, _ := json.Marshal(Result{Status: "OK", StatusMessage: "Current connection set", ConID: strings.ToUpper(connection.ID)})
+		fmt.Println(string(response))
+	} else {
+		logr.Printf("Connection %v is now the current connection", strings.ToUpper(connection.ID))
+		if envConID := os.Getenv(connections.DefaultConnectionEnv); envConID != "" {
+			logr.Warnf("%v is set to %v, which overrides the current connection", connections.DefaultConnectionEnv, envConID)
+		}
+	}
+	os.Exit(0)
+}
+
+// ConnectionMigrate : Migrates the connections config to the schema this cwctl uses, or shows the migration with --dry-run
+func ConnectionMigrate(c *cli.Context) {
+	dryRun := c.Bool("dry-run")
+	result, conErr := connections.MigrateConnectionsConfig(dryRun)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	if printAsJSON {
+		response, _ := json.Marshal(result)
+		fmt.Println(string(response))
+		os.Exit(0)
+	}
+
+	if len(result.Steps) == 0 {
+		fmt.Printf("Connections config is already at schema version %v\n", result.ToVersion)
+		os.Exit(0)
+	}
+	if dryRun {
+		fmt.Printf("Connections config would be migrated from schema version %v to %v:\n", result.FromVersion, result.ToVersion)
+	} else {
+		fmt.Printf("Connections config migrated from schema version %v to %v:\n", result.FromVersion, result.ToVersion)
+	}
+	for _, step := range result.Steps {
+		fmt.Println("  " + step)
+	}
+	if dryRun {
+		fmt.Println("Before:")
+		fmt.Println(string(result.Before))
+		fmt.Println("After:")
+		fmt.Println(string(result.After))
+	} else {
+		fmt.Println("The original file was saved to " + result.Backup)
+	}
+	os.Exit(0)
+}
+
+// connectionIDFromFlags : The connection given with --conid, or the current connection if --conid is not set
+func connectionIDFromFlags(c *cli.Context) string {
+	return connections.ResolveConnectionID(c.String("conid"))
+}
@@ -0,0 +1,103 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// ConnectionAddFromKube : Adds a connection for each Codewind deployment found in Kubernetes, skipping
+// deployments that already have a connection. The trust and proxy flags apply to every connection added.
+func ConnectionAddFromKube(c *cli.Context) {
+	for _, flag := range []string{"label", "url"} {
+		if strings.TrimSpace(c.String(flag)) != "" {
+			err := errors.New("--" + flag + " cannot be used with --from-kube, use connections update to change the connections it adds")
+			HandleConnectionError(&connections.ConError{Op: "con_conflict", Err: err, Desc: err.Error()})
+			os.Exit(1)
+		}
+	}
+	if strings.TrimSpace(c.String("username")) == "" {
+		err := errors.New("Required flag \"username\" not set, it is the username of every connection --from-kube adds")
+		HandleConnectionError(&connections.ConError{Op: "con_missing_flags", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+	deployments, remErr := remote.GetExistingDeployments(strings.TrimSpace(c.String("namespace")), nil)
+	if remErr != nil {
+		HandleRemInstError(remErr)
+		os.Exit(1)
+	}
+	settings := connections.ExportedConnection{
+		Username:        strings.TrimSpace(c.String("username")),
+		CertFingerprint: c.String("fingerprint"),
+		HTTPProxy:       c.String("http-proxy"),
+		HTTPSProxy:      c.String("https-proxy"),
+		SOCKSProxy:      c.String("socks5-proxy"),
+		NoProxy:         c.String("no-proxy"),
+	}
+	if caCert := strings.TrimSpace(c.String("cacert")); caCert != "" {
+		pem, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			HandleConnectionError(&connections.ConError{Op: "con_trust", Err: err, Desc: err.Error()})
+			os.Exit(1)
+		}
+		settings.CACert = string(pem)
+	}
+	export, unreachable := discoveredConnections(deployments, strings.TrimSpace(c.String("workspace")), settings)
+	if !printAsJSON {
+		for _, deployment := range unreachable {
+			logr.Warnf("Codewind deployment %v in namespace %v has no gatekeeper URL, it may be from an older version of Codewind", deployment.WorkspaceID, deployment.Namespace)
+		}
+	}
+	if len(export.Connections) == 0 {
+		err := errors.New("No Codewind deployments with a gatekeeper URL found")
+		HandleConnectionError(&connections.ConError{Op: "con_not_found", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	results, conErr := connections.ImportConnections(http.DefaultClient, export, connections.ImportSkip)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	printImportResults(results)
+}
+
+// discoveredConnections : The connections for the deployments of a workspace, or of every workspace when workspaceID
+// is empty, each labelled with its namespace and workspace ID and with the username, trust and proxy settings given.
+// Deployments without a gatekeeper URL are returned separately.
+func discoveredConnections(deployments []remote.ExistingDeployment, workspaceID string, settings connections.ExportedConnection) (*connections.ConnectionsExport, []remote.ExistingDeployment) {
+	export := &connections.ConnectionsExport{Connections: []connections.ExportedConnection{}}
+	unreachable := []remote.ExistingDeployment{}
+	for _, deployment := range deployments {
+		if workspaceID != "" && !strings.EqualFold(deployment.WorkspaceID, workspaceID) {
+			continue
+		}
+		if deployment.GatekeeperURL == "" {
+			unreachable = append(unreachable, deployment)
+			continue
+		}
+		connection := settings
+		connection.Label = deployment.Namespace + "/" + deployment.WorkspaceID
+		connection.URL = deployment.GatekeeperURL
+		export.Connections = append(export.Connections, connection)
+	}
+	return export, unreachable
+}
@@ -0,0 +1,48 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveredConnections(t *testing.T) {
+	deployments := []remote.ExistingDeployment{
+		{WorkspaceID: "WID1", Namespace: "team1", GatekeeperURL: "https://codewind-gatekeeper-WID1.nip.io"},
+		{WorkspaceID: "WID2", Namespace: "team2", GatekeeperURL: "https://codewind-gatekeeper-WID2.nip.io"},
+		{WorkspaceID: "WID3", Namespace: "team2"},
+	}
+
+	t.Run("every deployment with a gatekeeper URL, with the given settings", func(t *testing.T) {
+		export, unreachable := discoveredConnections(deployments, "", connections.ExportedConnection{Username: "developer", HTTPSProxy: "http://proxy.test:3128"})
+		assert.Equal(t, []connections.ExportedConnection{
+			{Label: "team1/WID1", URL: "https://codewind-gatekeeper-WID1.nip.io", Username: "developer", HTTPSProxy: "http://proxy.test:3128"},
+			{Label: "team2/WID2", URL: "https://codewind-gatekeeper-WID2.nip.io", Username: "developer", HTTPSProxy: "http://proxy.test:3128"},
+		}, export.Connections)
+		assert.Equal(t, []remote.ExistingDeployment{deployments[2]}, unreachable)
+	})
+	t.Run("only the given workspace", func(t *testing.T) {
+		export, unreachable := discoveredConnections(deployments, "wid2", connections.ExportedConnection{})
+		assert.Len(t, export.Connections, 1)
+		assert.Equal(t, "team2/WID2", export.Connections[0].Label)
+		assert.Empty(t, unreachable)
+	})
+	t.Run("an unknown workspace", func(t *testing.T) {
+		export, unreachable := discoveredConnections(deployments, "WID9", connections.ExportedConnection{})
+		assert.Empty(t, export.Connections)
+		assert.Empty(t, unreachable)
+	})
+}
@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// ConnectionHealth : The results of the health checks of one connection
+type ConnectionHealth struct {
+	ID       string                        `json:"id"`
+	Label    string                        `json:"label"`
+	URL      string                        `json:"url"`
+	Healthy  bool                          `json:"healthy"`
+	Checks   []connections.ConnectionCheck `json:"checks"`
+	Versions *apiroutes.ContainerVersions  `json:"versions,omitempty"`
+}
+
+// ConnectionStatus : Checks the health of every connection at once and prints the results
+func ConnectionStatus(c *cli.Context) {
+	timeout, err := time.ParseDuration(strings.TrimSpace(c.String("timeout")))
+	if err != nil || timeout <= 0 {
+		if err == nil {
+			err = errors.New("timeout must be greater than zero")
+		}
+		HandleConnectionError(&connections.ConError{Op: "con_options_invalid", Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+
+	results := checkAllConnections(&http.Client{Timeout: timeout}, allConnections, timeout)
+	if printAsJSON {
+		jsonResults, _ := json.Marshal(results)
+		fmt.Println(string(jsonResults))
+	} else {
+		printConnectionHealth(results)
+	}
+
+	for _, result := range results {
+		if !result.Healthy {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// checkAllConnections checks each connection in its own goroutine and returns the results in the order of the connections
+func checkAllConnections(httpClient utils.HTTPClient, allConnections []connections.Connection, timeout time.Duration) []ConnectionHealth {
+	results := make([]ConnectionHealth, len(allConnections))
+	var wg sync.WaitGroup
+	for i := range allConnections {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = checkConnection(httpClient, &allConnections[i], timeout)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkConnection looks up what the health checks of a connection need from the keyring and the Codewind
+// server's configuration, runs them, then checks the container versions
+func checkConnection(httpClient utils.HTTPClient, connection *connections.Connection, timeout time.Duration) ConnectionHealth {
+	health := ConnectionHealth{ID: connection.ID, Label: connection.Label, URL: connection.URL}
+	options := connections.HealthCheckOptions{Timeout: timeout}
+	conURL, confErr := config.PFEOriginFromConnection(connection)
+	if confErr != nil {
+		options.PFEURLError = confErr.Desc
+	}
+	options.PFEURL = conURL
+	if !strings.EqualFold(connection.ID, "local") {
+		conID := strings.ToLower(connection.ID)
+		options.AccessToken, _ = security.GetSecretFromKeyring(conID, "access_token")
+		options.RefreshToken, _ = security.GetSecretFromKeyring(conID, "refresh_token")
+	}
+	health.Checks = connections.CheckConnection(httpClient, connection, options)
+
+	health.Checks = append(health.Checks, connections.RunConnectionCheck(connections.CheckVersions, func() (string, string) {
+		if confErr != nil {
+			return connections.CheckFailed, confErr.Desc
+		}
+		versionsClient, conErr := connections.ConnectionHTTPClient(httpClient, connection)
+		if conErr != nil {
+			return connections.CheckFailed, conErr.Desc
+		}
+		versions, err := apiroutes.GetContainerVersions(conURL, "", connection, versionsClient)
+		if err != nil {
+			return connections.CheckFailed, err.Error()
+		}
+		health.Versions = &versions
+		return connections.CheckOK, "PFE " + versions.PFEVersion
+	}))
+
+	health.Healthy = connections.ConnectionHealthy(health.Checks)
+	return health
+}
+
+// printConnectionHealth prints a row of check results per connection, followed by the details of each check that did not pass
+func printConnectionHealth(results []ConnectionHealth) {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "CONNECTION ID\tLABEL\tHEALTHY\tGATEKEEPER\tTLS\tTOKEN\tPFE READY\tVERSIONS")
+	for _, result := range results {
+		row := []string{result.ID, result.Label, fmt.Sprintf("%t", result.Healthy)}
+		for _, check := range result.Checks {
+			cell := check.Status
+			if check.Status != connections.CheckSkipped {
+				cell += fmt.Sprintf(" (%dms)", check.DurationMs)
+			}
+			row = append(row, cell)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	for _, result := range results {
+		for _, check := range result.Checks {
+			if (check.Status == connections.CheckFailed || check.Status == connections.CheckWarning) && check.Detail != "" {
+				fmt.Println(result.ID + " " + check.Name + ": " + check.Detail)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAllConnections(t *testing.T) {
+	remoteConnections := []connections.Connection{
+		{ID: "REMOTE1", Label: "first", URL: "http://first.test"},
+		{ID: "REMOTE2", Label: "second", URL: "http://second.test"},
+	}
+	results := checkAllConnections(&security.ClientMockRequestFail{}, remoteConnections, time.Second)
+
+	assert.Len(t, results, 2)
+	for i, result := range results {
+		assert.Equal(t, remoteConnections[i].ID, result.ID)
+		assert.False(t, result.Healthy)
+		checks := map[string]string{}
+		for _, check := range result.Checks {
+			checks[check.Name] = check.Status
+		}
+		assert.Equal(t, connections.CheckFailed, checks[connections.CheckGatekeeper])
+		assert.Equal(t, connections.CheckSkipped, checks[connections.CheckTLS])
+		assert.Equal(t, connections.CheckFailed, checks[connections.CheckPFEReady])
+		assert.Equal(t, connections.CheckFailed, checks[connections.CheckVersions])
+		assert.Nil(t, result.Versions)
+	}
+}
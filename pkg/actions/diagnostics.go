@@ -96,7 +96,7 @@ func warnDG(warning, description string) {
 //DiagnosticsCollect to gather logs and project files to aid diagnosis of Codewind errors
 func DiagnosticsCollect(c *cli.Context) {
 	collectingAll = c.Bool("all")
-	connectionID := c.String("conid")
+	connectionID := connectionIDFromFlags(c)
 	dirErr := os.MkdirAll(diagnosticsDirName, 0755)
 	if dirErr != nil {
 		errors.CheckErr(dirErr, 205, "")
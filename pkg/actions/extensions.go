@@ -0,0 +1,179 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// ExtensionTestResult reports which extension would be used for a local project, and why
+type ExtensionTestResult struct {
+	Path        string                  `json:"projectPath"`
+	Matched     bool                    `json:"matched"`
+	ProjectType string                  `json:"projectType,omitempty"`
+	Command     *utils.ExtensionCommand `json:"command,omitempty"`
+	Extensions  []utils.ExtensionMatch  `json:"extensions"`
+}
+
+// ListExtensions lists the project extensions installed on a connection
+func ListExtensions(c *cli.Context) {
+	conID := connectionIDFromFlags(c)
+	extensions, err := apiroutes.GetExtensions(conID)
+	if err != nil {
+		HandleExtensionError(&ExtensionError{errOpListExtensions, err, err.Error()})
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(extensions)
+		fmt.Println(string(jsonResponse))
+	} else if len(extensions) == 0 {
+		fmt.Println("No extensions installed")
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "PROJECT TYPE \tDETECTION \tSTYLE \tCOMMANDS")
+		for _, extension := range extensions {
+			commandNames := []string{}
+			for _, command := range extension.Commands {
+				commandNames = append(commandNames, command.Name)
+			}
+			fmt.Fprintln(w, extension.ProjectType+"\t"+extension.Detection+"\t"+extension.Config.Style+"\t"+strings.Join(commandNames, ", "))
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+	os.Exit(0)
+}
+
+// ShowExtension prints the details of the extension for a project type
+func ShowExtension(c *cli.Context) {
+	conID := connectionIDFromFlags(c)
+	projectType := strings.TrimSpace(c.Args().First())
+	if projectType == "" {
+		err := errors.New("Must specify the project type of the extension to show")
+		HandleExtensionError(&ExtensionError{errOpShowExtension, err, err.Error()})
+		os.Exit(1)
+	}
+
+	extensions, err := apiroutes.GetExtensions(conID)
+	if err != nil {
+		HandleExtensionError(&ExtensionError{errOpShowExtension, err, err.Error()})
+		os.Exit(1)
+	}
+
+	var extension *utils.Extension
+	for i := range extensions {
+		if extensions[i].ProjectType == projectType {
+			extension = &extensions[i]
+			break
+		}
+	}
+	if extension == nil {
+		err := errors.New("No extension found for project type " + projectType + " on connection " + conID)
+		HandleExtensionError(&ExtensionError{errOpExtensionNotFound, err, err.Error()})
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(extension)
+		fmt.Println(string(jsonResponse))
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "Project type:\t"+extension.ProjectType)
+		fmt.Fprintln(w, "Detection file:\t"+extension.Detection)
+		fmt.Fprintln(w, "Style:\t"+extension.Config.Style)
+		fmt.Fprintln(w)
+		if len(extension.Commands) == 0 {
+			fmt.Fprintln(w, "Extension has no commands")
+		} else {
+			fmt.Fprintln(w, "COMMAND NAME \tCOMMAND \tARGS \tTIMEOUT")
+			for _, command := range extension.Commands {
+				timeout := "none"
+				if command.Timeout > 0 {
+					timeout = strconv.Itoa(command.Timeout) + "s"
+				}
+				fmt.Fprintln(w, command.Name+"\t"+command.Command+"\t"+strings.Join(command.Args, " ")+"\t"+timeout)
+			}
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+	os.Exit(0)
+}
+
+// TestExtensions reports which extension would be used when validating a local project, and why
+func TestExtensions(c *cli.Context) {
+	conID := connectionIDFromFlags(c)
+	projectPath := strings.TrimSpace(c.String("path"))
+	projectType := strings.Split(strings.TrimSpace(c.String("type")), ":")[0]
+
+	if !utils.PathExists(projectPath) {
+		err := errors.New("Project path " + projectPath + " does not exist")
+		HandleExtensionError(&ExtensionError{errOpTestExtensions, err, err.Error()})
+		os.Exit(1)
+	}
+
+	extensions, err := apiroutes.GetExtensions(conID)
+	if err != nil {
+		HandleExtensionError(&ExtensionError{errOpTestExtensions, err, err.Error()})
+		os.Exit(1)
+	}
+
+	extension, matches := utils.MatchExtension(extensions, projectPath, projectType)
+	result := ExtensionTestResult{Path: projectPath, Extensions: matches}
+	if extension != nil {
+		commandName := utils.CommandPostProjectValidate
+		if projectType != "" {
+			commandName = utils.CommandPostProjectValidateWithType
+		}
+		result.Matched = true
+		result.ProjectType = extension.ProjectType
+		result.Command = utils.FindCommand(*extension, commandName)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(result)
+		fmt.Println(string(jsonResponse))
+	} else {
+		if !result.Matched {
+			fmt.Println("No extension matches the project at " + projectPath)
+		} else if result.Command == nil {
+			fmt.Println("The " + result.ProjectType + " extension matches the project at " + projectPath + ", no command would be run")
+		} else {
+			fmt.Println("The " + result.ProjectType + " extension matches the project at " + projectPath + ", its " + result.Command.Name + " command would be run")
+		}
+		if len(matches) > 0 {
+			w := new(tabwriter.Writer)
+			w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "PROJECT TYPE \tMATCHED \tREASON")
+			for _, match := range matches {
+				fmt.Fprintln(w, match.ProjectType+"\t"+strconv.FormatBool(match.Matched)+"\t"+match.Reason)
+			}
+			fmt.Fprintln(w)
+			w.Flush()
+		}
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,39 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import "encoding/json"
+
+// ExtensionError struct will format the error
+type ExtensionError struct {
+	Op   string
+	Err  error
+	Desc string
+}
+
+const (
+	errOpListExtensions    = "LIST_EXTENSIONS_ERROR"
+	errOpShowExtension     = "SHOW_EXTENSION_ERROR"
+	errOpTestExtensions    = "TEST_EXTENSIONS_ERROR"
+	errOpExtensionNotFound = "EXTENSION_NOT_FOUND_ERROR"
+)
+
+// ExtensionError : Error formatted in JSON containing an errorOp and a description
+func (ee *ExtensionError) Error() string {
+	type Output struct {
+		Operation   string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	tempOutput := &Output{Operation: ee.Op, Description: ee.Err.Error()}
+	jsonError, _ := json.Marshal(tempOutput)
+	return string(jsonError)
+}
@@ -27,7 +27,7 @@ import (
 // LogLevels : Optionally set then display the log level information
 // for a Codewind PFE container
 func LogLevels(c *cli.Context) {
-	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	connectionID := connectionIDFromFlags(c)
 	newLogLevel := strings.TrimSpace(strings.ToLower(c.Args().Get(0)))
 
 	conInfo, conInfoErr := connections.GetConnectionByID(connectionID)
@@ -12,16 +12,24 @@
 package actions
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/remote"
 	"github.com/eclipse/codewind-installer/pkg/templates"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
@@ -32,7 +40,12 @@ import (
 func ProjectValidate(c *cli.Context) {
 	response, projectErr := project.ValidateProject(c)
 	if projectErr != nil {
-		fmt.Println(projectErr.Error())
+		if response != nil {
+			// a failed extension command, which the response reports
+			printFailedValidation(response)
+		} else {
+			fmt.Println(projectErr.Error())
+		}
 		os.Exit(1)
 	}
 	projectInfo, _ := json.Marshal(response)
@@ -40,15 +53,36 @@ func ProjectValidate(c *cli.Context) {
 	os.Exit(0)
 }
 
-// ProjectCreate : Downloads template, create a new project then validate it
+// printFailedValidation : prints a failed validation, including the exit code and output of the extension command
+func printFailedValidation(response *project.ValidationResponse) {
+	projectInfo, _ := json.Marshal(response)
+	fmt.Println(string(projectInfo))
+}
+
+// ProjectCreate : Downloads template, create a new project then validate it, and bind it if requested
 func ProjectCreate(c *cli.Context) {
 	destination := c.String("path")
 	url := c.String("url")
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	templateName := c.String("template")
+	conID := connectionIDFromFlags(c)
 	username := c.String("username")
 	password := c.String("password")
 	personalAccessToken := c.String("personalAccessToken")
 
+	if (url == "") == (templateName == "") {
+		logr.Errorln("Must specify either a template URL (--url) or a template name (--template)")
+		os.Exit(1)
+	}
+
+	if templateName != "" {
+		template, templateErr := project.GetTemplateForConnection(conID, templateName)
+		if templateErr != nil {
+			HandleProjectError(templateErr)
+			os.Exit(1)
+		}
+		url = template.URL
+	}
+
 	gitCredentials, err := utils.ExtractGitCredentials(username, password, personalAccessToken)
 	if err != nil {
 		templateErr := &TemplateError{errOpAddRepo, err, err.Error()}
@@ -75,12 +109,61 @@ func ProjectCreate(c *cli.Context) {
 	} else {
 		logr.Tracef("Project downloaded to %v", destination)
 	}
-	ProjectValidate(c)
+	if !c.Bool("bind") {
+		ProjectValidate(c)
+	}
+
+	validation, validateErr := project.ValidateProject(c)
+	if validateErr != nil {
+		if validation != nil {
+			printFailedValidation(validation)
+		} else {
+			HandleProjectError(validateErr)
+		}
+		os.Exit(1)
+	}
+	response, bindErr := project.CreateAndBind(validation, conID)
+	if bindErr != nil {
+		HandleProjectError(bindErr)
+		os.Exit(1)
+	}
+	jsonResponse, _ := json.Marshal(response)
+	fmt.Println(string(jsonResponse))
+	os.Exit(0)
 }
 
 // ProjectSync : Does a project Sync
 func ProjectSync(c *cli.Context) {
-	response, err := project.SyncProject(c)
+	if isBulkOperation(c) {
+		projects := selectProjects(c)
+		results := project.RunBulk(projects, c.Int("concurrency"), func(p project.Project) error {
+			// a full sync, as we don't know when each project was last synced
+			_, projErr := project.SyncProjectByID(p.LocationOnDisk, p.ProjectID, 0)
+			if projErr != nil {
+				return projErr
+			}
+			return nil
+		})
+		printBulkResults("sync", results)
+	}
+
+	// a single project is synced from the time it was last synced, which the caller must give along with its path
+	projectPath := strings.TrimSpace(c.String("path"))
+	syncTime := strings.TrimSpace(c.String("time"))
+	if projectPath == "" || syncTime == "" {
+		err := errors.New("--path and --time are required to sync a project, or use --all or the other selector flags to sync every matching project")
+		HandleProjectError(&project.ProjectError{Op: project.ErrOpInvalidOptions, Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+	lastSync, parseErr := strconv.ParseInt(syncTime, 10, 64)
+	if parseErr != nil {
+		err := errors.New("--time must be a UNIX timestamp in milliseconds: " + syncTime)
+		HandleProjectError(&project.ProjectError{Op: project.ErrOpInvalidOptions, Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	projectID, _ := projectIDFromFlags(c)
+	response, err := project.SyncProjectByID(projectPath, projectID, lastSync)
 	if err != nil {
 		HandleProjectError(err)
 		os.Exit(1)
@@ -115,7 +198,29 @@ func ProjectBind(c *cli.Context) {
 
 // ProjectRemove : Does a project remove
 func ProjectRemove(c *cli.Context) {
-	err := project.RemoveProject(c)
+	if isBulkOperation(c) {
+		deleteFiles := c.Bool("delete")
+		projects := selectProjects(c)
+		question := fmt.Sprintf("Remove %d projects from Codewind", len(projects))
+		if deleteFiles {
+			question += " and delete their local files"
+		}
+		if len(projects) > 0 && !c.Bool("yes") && !confirm(os.Stdin, os.Stderr, projects, question) {
+			fmt.Println("No projects removed")
+			os.Exit(1)
+		}
+		results := project.RunBulk(projects, c.Int("concurrency"), func(p project.Project) error {
+			projErr := project.RemoveProjectByID(http.DefaultClient, p.ProjectID, deleteFiles)
+			if projErr != nil {
+				return projErr
+			}
+			return nil
+		})
+		printBulkResults("remove", results)
+	}
+
+	projectID, _ := projectIDFromFlags(c)
+	err := project.RemoveProjectByID(http.DefaultClient, projectID, c.Bool("delete"))
 	if err != nil {
 		HandleProjectError(err)
 		os.Exit(1)
@@ -126,7 +231,9 @@ func ProjectRemove(c *cli.Context) {
 // UpgradeProjects : Upgrades projects
 func UpgradeProjects(c *cli.Context) {
 	dir := strings.TrimSpace(c.String("workspace"))
-	response, err := project.UpgradeProjects(dir)
+	conID := connectionIDFromFlags(c)
+	dryRun := c.Bool("dry-run")
+	response, err := project.UpgradeProjects(http.DefaultClient, dir, conID, dryRun)
 	if err != nil {
 		HandleProjectError(err)
 		os.Exit(1)
@@ -137,7 +244,7 @@ func UpgradeProjects(c *cli.Context) {
 
 // ProjectList : Print the list of projects to the terminal
 func ProjectList(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
@@ -151,13 +258,27 @@ func ProjectList(c *cli.Context) {
 		os.Exit(1)
 	}
 
+	filters := []project.ProjectFilter{}
+	for _, filterText := range c.StringSlice("filter") {
+		filter, filterErr := project.ParseProjectFilter(filterText)
+		if filterErr != nil {
+			HandleProjectError(filterErr)
+			os.Exit(1)
+		}
+		filters = append(filters, *filter)
+	}
+	fields := fieldsFromFlags(c)
+
 	projects, getAllErr := project.GetAll(http.DefaultClient, conInfo, conURL)
 	if getAllErr != nil {
 		HandleProjectError(getAllErr)
 		os.Exit(1)
 	}
+	projects = project.FilterProjects(projects, filters)
 
-	if printAsJSON {
+	if len(fields) > 0 {
+		printProjectFields(projects, fields)
+	} else if printAsJSON {
 		json, _ := json.Marshal(projects)
 		fmt.Println(string(json))
 	} else {
@@ -178,18 +299,51 @@ func ProjectList(c *cli.Context) {
 	os.Exit(0)
 }
 
-// ProjectGet : Prints information about a given project using its ID
-func ProjectGet(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
-	projectName := c.String("name")
+// fieldsFromFlags returns the project fields given with --fields
+func fieldsFromFlags(c *cli.Context) []string {
+	fields := []string{}
+	for _, field := range strings.Split(c.String("fields"), ",") {
+		if strings.TrimSpace(field) != "" {
+			fields = append(fields, strings.TrimSpace(field))
+		}
+	}
+	return fields
+}
 
-	if projectID == "" && projectName == "" {
-		logr.Errorln("Must specify either project ID (--id) or project name (--name)")
-		os.Exit(1)
+// printProjectFields prints the given fields of each project, as a table or as JSON
+func printProjectFields(projects []project.Project, fields []string) {
+	if printAsJSON {
+		selected := []map[string]interface{}{}
+		for _, p := range projects {
+			selected = append(selected, project.SelectProjectFields(p, fields))
+		}
+		jsonResponse, _ := json.Marshal(selected)
+		fmt.Println(string(jsonResponse))
+		return
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(fields, " \t")))
+	for _, p := range projects {
+		values := []string{}
+		for _, field := range fields {
+			value, _ := project.ProjectFieldValue(p, field)
+			values = append(values, project.FormatFieldValue(value))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
 	}
+	fmt.Fprintln(w)
+	w.Flush()
+}
+
+// ProjectGet : Prints information about a given project using its ID
+func ProjectGet(c *cli.Context) {
+	conID := connectionIDFromFlags(c)
+	projectID, projectConID := projectIDFromFlags(c)
 
-	if projectID != "" && (conID == "local" || conID == "") {
+	if projectConID != "" {
+		conID = projectConID
+	} else if conID == "local" {
 		newConID, conIDErr := project.GetConnectionID(projectID)
 		if conIDErr != nil {
 			HandleProjectError(conIDErr)
@@ -210,20 +364,15 @@ func ProjectGet(c *cli.Context) {
 		os.Exit(1)
 	}
 
-	var projectObj *project.Project
-	var projectErr *project.ProjectError
-	if projectID == "" && projectName != "" {
-		projectObj, projectErr = project.GetProjectFromName(http.DefaultClient, conInfo, conURL, projectName)
-	} else {
-		projectObj, projectErr = project.GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
-	}
-
+	projectObj, projectErr := project.GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
 	if projectErr != nil {
 		HandleProjectError(projectErr)
 		os.Exit(1)
 	}
 
-	if printAsJSON {
+	if fields := fieldsFromFlags(c); len(fields) > 0 {
+		printProjectFields([]project.Project{*projectObj}, fields)
+	} else if printAsJSON {
 		json, _ := json.Marshal(projectObj)
 		fmt.Println(string(json))
 	} else {
@@ -240,9 +389,17 @@ func ProjectGet(c *cli.Context) {
 
 // ProjectRestart : restarts a project
 func ProjectRestart(c *cli.Context) {
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	startMode := strings.TrimSpace(c.String("startmode"))
+	bulk := isBulkOperation(c)
+	projectID := ""
+	if !bulk {
+		var projectConID string
+		projectID, projectConID = projectIDFromFlags(c)
+		if projectConID != "" {
+			conID = projectConID
+		}
+	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
@@ -256,6 +413,14 @@ func ProjectRestart(c *cli.Context) {
 		os.Exit(1)
 	}
 
+	if bulk {
+		projects := selectProjects(c)
+		results := project.RunBulk(projects, c.Int("concurrency"), func(p project.Project) error {
+			return project.RestartProject(http.DefaultClient, conInfo, conURL, p.ProjectID, startMode)
+		})
+		printBulkResults("restart", results)
+	}
+
 	err := project.RestartProject(http.DefaultClient, conInfo, conURL, projectID, startMode)
 	if err != nil {
 		fmt.Println(err.Error())
@@ -267,16 +432,246 @@ func ProjectRestart(c *cli.Context) {
 	os.Exit(0)
 }
 
-// ProjectLinkList : lists all the links for a project
-func ProjectLinkList(c *cli.Context) {
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+// ProjectLogs : prints the build or app log of a project, one line at a time
+func ProjectLogs(c *cli.Context) {
+	projectID, conID := projectAndConnectionFromFlags(c)
+	options := project.LogOptions{
+		Type:   strings.TrimSpace(strings.ToLower(c.String("type"))),
+		Follow: c.Bool("follow"),
+		Since:  strings.TrimSpace(c.String("since")),
+		Tail:   strings.TrimSpace(strings.ToLower(c.String("tail"))),
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	projErr := project.StreamProjectLogs(http.DefaultClient, conInfo, conURL, projectID, options, func(line project.LogLine) {
+		if printAsJSON {
+			jsonLine, _ := json.Marshal(line)
+			fmt.Println(string(jsonLine))
+		} else {
+			fmt.Println(line.Line)
+		}
+	})
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectWait : waits for a project to be started, stopped or built, printing each change of its status
+func ProjectWait(c *cli.Context) {
+	projectID, conID := projectAndConnectionFromFlags(c)
+	waitFor := strings.TrimSpace(strings.ToLower(c.String("for")))
+	timeout, err := time.ParseDuration(strings.TrimSpace(c.String("timeout")))
+	if err != nil {
+		HandleProjectError(&project.ProjectError{Op: project.ErrOpInvalidOptions, Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	options := project.WaitOptions{For: waitFor, Timeout: timeout}
+	_, projErr := project.WaitForProject(http.DefaultClient, conInfo, conURL, projectID, options, func(status project.ProjectStatus) {
+		if printAsJSON {
+			jsonStatus, _ := json.Marshal(status)
+			fmt.Println(string(jsonStatus))
+		} else {
+			fmt.Println(status.Time + " app status: " + status.AppStatus + ", build status: " + status.BuildStatus)
+		}
+	})
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(project.ExitCode(projErr))
+	}
+	os.Exit(0)
+}
+
+// ProjectDebug : restarts a project in debug mode if needed and makes its debug port reachable on this machine,
+// forwarding it from the cluster for remote connections until interrupted
+func ProjectDebug(c *cli.Context) {
+	projectID, conID := projectAndConnectionFromFlags(c)
+	timeout, err := time.ParseDuration(strings.TrimSpace(c.String("timeout")))
+	if err != nil {
+		HandleProjectError(&project.ProjectError{Op: project.ErrOpInvalidOptions, Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	debugProject, restarted, projErr := project.PrepareDebug(http.DefaultClient, conInfo, conURL, projectID, project.WaitOptions{Timeout: timeout}, func(status project.ProjectStatus) {
+		if !printAsJSON {
+			fmt.Println(status.Time + " app status: " + status.AppStatus + ", build status: " + status.BuildStatus)
+		}
+	})
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	target := project.DebugTarget{ProjectID: projectID, ConnectionID: conID, Restarted: restarted}
+	if conID == "local" {
+		target.Host, target.Port, projErr = project.LocalDebugAddress(debugProject)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+		printDebugTarget(target, "")
+		os.Exit(0)
+	}
+
+	namespace, remErr := remote.GetWorkspaceNamespace(strings.Replace(conInfo.ClientID, codewindPrefix, "", 1))
+	if remErr != nil {
+		HandleRemInstError(remErr)
+		os.Exit(1)
+	}
+	stopChan := make(chan struct{})
+	target.Host, target.PortForwarded = "localhost", true
+	port, doneChan, remErr := remote.PortForward(namespace, debugProject.PodName, string(debugProject.Ports.InternalDebugPort), stopChan)
+	if remErr != nil {
+		HandleRemInstError(remErr)
+		os.Exit(1)
+	}
+	target.Port = port
+	printDebugTarget(target, ", press Ctrl+C to stop")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-signals:
+		close(stopChan)
+	case remErr := <-doneChan:
+		if remErr != nil {
+			HandleRemInstError(remErr)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// printDebugTarget : prints where to attach a debugger, followed by a hint when not printing JSON
+func printDebugTarget(target project.DebugTarget, hint string) {
+	if printAsJSON {
+		jsonTarget, _ := json.Marshal(target)
+		fmt.Println(string(jsonTarget))
+	} else {
+		fmt.Println("Attach a debugger to " + target.Host + ":" + target.Port + hint)
+	}
+}
+
+// ProjectMove : moves a project from its current connection to another connection
+func ProjectMove(c *cli.Context) {
+	projectID, _ := projectIDFromFlags(c)
+	targetConID := strings.TrimSpace(strings.ToLower(c.String("to")))
 
-	conID, getConnectionIDErr := project.GetConnectionID(projectID)
-	if getConnectionIDErr != nil {
-		HandleProjectError(getConnectionIDErr)
+	response, projErr := project.MoveProject(http.DefaultClient, projectID, targetConID)
+	if projErr != nil {
+		HandleProjectError(projErr)
 		os.Exit(1)
 	}
 
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(response)
+		fmt.Println(string(jsonResponse))
+	} else {
+		fmt.Println("Project moved from connection " + response.SourceConnectionID + " to " + response.TargetConnectionID + " with ID " + response.Bind.ProjectID)
+		for _, link := range response.SkippedLinks {
+			fmt.Println("Link " + link.Link.EnvName + " to " + link.Link.ProjectName + " was not restored: " + link.Reason)
+		}
+	}
+	os.Exit(0)
+}
+
+// ProjectExport : writes a project, its settings, links and bind metadata to a bundle file
+func ProjectExport(c *cli.Context) {
+	projectID, conID := projectAndConnectionFromFlags(c)
+	bundlePath := strings.TrimSpace(c.String("output"))
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	response, projErr := project.ExportProject(http.DefaultClient, conInfo, conURL, projectID, bundlePath)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(response)
+		fmt.Println(string(jsonResponse))
+	} else {
+		fmt.Println("Project " + response.Manifest.Name + " exported to " + response.BundlePath)
+	}
+	os.Exit(0)
+}
+
+// ProjectImport : recreates a project from a bundle file, binds it and restores its links
+func ProjectImport(c *cli.Context) {
+	bundlePath := strings.TrimSpace(c.String("bundle"))
+	projectPath := strings.TrimSpace(c.String("path"))
+	conID := connectionIDFromFlags(c)
+
+	response, projErr := project.ImportProject(http.DefaultClient, bundlePath, projectPath, conID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(response)
+		fmt.Println(string(jsonResponse))
+	} else {
+		fmt.Println("Project " + response.Manifest.Name + " imported to " + response.Path + " with ID " + response.Bind.ProjectID)
+		for _, link := range response.SkippedLinks {
+			fmt.Println("Link " + link.Link.EnvName + " to " + link.Link.ProjectName + " was not restored: " + link.Reason)
+		}
+	}
+	os.Exit(0)
+}
+
+// ProjectLinkList : lists all the links for a project
+func ProjectLinkList(c *cli.Context) {
+	projectID, conID := projectAndConnectionFromFlags(c)
+
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
@@ -317,16 +712,11 @@ func ProjectLinkList(c *cli.Context) {
 
 // ProjectLinkCreate : creates a new link
 func ProjectLinkCreate(c *cli.Context) {
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
-	targetProjectID := strings.TrimSpace(strings.ToLower(c.String("targetID")))
+	projectID, conID := projectAndConnectionFromFlags(c)
+	// a link target is always on the same connection as the project
+	targetProjectID, _ := resolveProjectFlags(c, "targetID", "targetName", conID)
 	envName := strings.TrimSpace(c.String("env"))
 
-	conID, getConnectionIDErr := project.GetConnectionID(projectID)
-	if getConnectionIDErr != nil {
-		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
-	}
-
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
@@ -352,16 +742,10 @@ func ProjectLinkCreate(c *cli.Context) {
 
 // ProjectLinkUpdate : updates a link
 func ProjectLinkUpdate(c *cli.Context) {
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	projectID, conID := projectAndConnectionFromFlags(c)
 	envName := strings.TrimSpace(c.String("env"))
 	updatedEnvName := strings.TrimSpace(c.String("newEnv"))
 
-	conID, getConnectionIDErr := project.GetConnectionID(projectID)
-	if getConnectionIDErr != nil {
-		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
-	}
-
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
@@ -387,15 +771,9 @@ func ProjectLinkUpdate(c *cli.Context) {
 
 // ProjectLinkDelete : deletes a link
 func ProjectLinkDelete(c *cli.Context) {
-	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	projectID, conID := projectAndConnectionFromFlags(c)
 	envName := strings.TrimSpace(c.String("env"))
 
-	conID, getConnectionIDErr := project.GetConnectionID(projectID)
-	if getConnectionIDErr != nil {
-		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
-	}
-
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
@@ -418,3 +796,288 @@ func ProjectLinkDelete(c *cli.Context) {
 	fmt.Println(string(response))
 	os.Exit(0)
 }
+
+// ProjectSettingsGet : prints a setting, or the whole .cw-settings file if no setting is given
+func ProjectSettingsGet(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	key := strings.TrimSpace(c.Args().First())
+
+	if key == "" {
+		settings, projErr := project.LoadSettingsFile(projectPath)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+		fmt.Print(string(settings.Bytes()))
+		os.Exit(0)
+	}
+
+	value, projErr := project.GetSetting(projectPath, key)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	fmt.Println(string(value))
+	os.Exit(0)
+}
+
+// ProjectSettingsSet : sets a setting in the .cw-settings file
+func ProjectSettingsSet(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	projectType := strings.TrimSpace(c.String("type"))
+	if c.NArg() != 2 {
+		logr.Errorln("Must specify the setting and the value to set it to")
+		os.Exit(1)
+	}
+	key := strings.TrimSpace(c.Args().Get(0))
+	value := c.Args().Get(1)
+
+	_, projErr := project.UpdateSetting(projectPath, projectType, key, value)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Setting " + key + " updated"})
+	fmt.Println(string(response))
+	os.Exit(0)
+}
+
+// ProjectSettingsUnset : removes a setting from the .cw-settings file
+func ProjectSettingsUnset(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	key := strings.TrimSpace(c.Args().First())
+	if key == "" {
+		logr.Errorln("Must specify the setting to remove")
+		os.Exit(1)
+	}
+
+	_, projErr := project.RemoveSetting(projectPath, key)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Setting " + key + " removed"})
+	fmt.Println(string(response))
+	os.Exit(0)
+}
+
+// ProjectSettingsValidate : validates the .cw-settings file against the schema for the project type
+func ProjectSettingsValidate(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	projectType := strings.TrimSpace(c.String("type"))
+
+	response, projErr := project.ValidateSettings(projectPath, projectType)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(response)
+		fmt.Println(string(jsonResponse))
+	} else if len(response.Issues) == 0 {
+		fmt.Println("Settings are valid for project type " + response.ProjectType)
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "SETTING \tPROBLEM")
+		for _, issue := range response.Issues {
+			fmt.Fprintln(w, issue.Field+"\t"+issue.Message)
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+
+	if response.Status != "success" {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// isBulkOperation reports whether a command is to work on the projects matching its selector flags rather
+// than a single project ID. It exits if both, or neither, are given.
+func isBulkOperation(c *cli.Context) bool {
+	hasProject := strings.TrimSpace(c.String("id")) != "" || strings.TrimSpace(c.String("name")) != ""
+	projErr := project.ValidateSelection(hasProject, projectSelectorFromFlags(c))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	return !hasProject
+}
+
+// projectIDFromFlags returns the project ID given with --id, or the ID of the project named with --name.
+// A name is looked up on the connection given with --conid, or on every connection if --conid is not set,
+// and the connection it was found on is returned too.
+func projectIDFromFlags(c *cli.Context) (string, string) {
+	conID := ""
+	if c.IsSet("conid") {
+		conID = strings.TrimSpace(strings.ToLower(c.String("conid")))
+	}
+	return resolveProjectFlags(c, "id", "name", conID)
+}
+
+// projectAndConnectionFromFlags returns the project given with --id or --name, and the connection it is on
+func projectAndConnectionFromFlags(c *cli.Context) (string, string) {
+	projectID, conID := projectIDFromFlags(c)
+	if conID == "" {
+		var getConnectionIDErr *project.ProjectError
+		conID, getConnectionIDErr = project.GetConnectionID(projectID)
+		if getConnectionIDErr != nil {
+			HandleProjectError(getConnectionIDErr)
+			os.Exit(1)
+		}
+	}
+	return projectID, conID
+}
+
+// resolveProjectFlags returns the project ID given with the ID flag, or looks up the project named with the name flag
+func resolveProjectFlags(c *cli.Context, idFlag, nameFlag, conID string) (string, string) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String(idFlag)))
+	projectName := strings.TrimSpace(c.String(nameFlag))
+	projectID, projectConID, projErr := project.ResolveProjectID(http.DefaultClient, projectID, projectName, conID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	return projectID, projectConID
+}
+
+func projectSelectorFromFlags(c *cli.Context) project.ProjectSelector {
+	return project.ProjectSelector{
+		All:         c.Bool("all"),
+		NamePattern: strings.TrimSpace(c.String("name-pattern")),
+		Language:    strings.TrimSpace(c.String("language")),
+		Status:      strings.TrimSpace(c.String("status")),
+	}
+}
+
+// selectProjects returns the projects on the connection given by --conid that match the selector flags
+func selectProjects(c *cli.Context) []project.Project {
+	conID := connectionIDFromFlags(c)
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	projects, getAllErr := project.GetAll(http.DefaultClient, conInfo, conURL)
+	if getAllErr != nil {
+		HandleProjectError(getAllErr)
+		os.Exit(1)
+	}
+
+	selected, selectErr := project.SelectProjects(projects, projectSelectorFromFlags(c))
+	if selectErr != nil {
+		HandleProjectError(selectErr)
+		os.Exit(1)
+	}
+	return selected
+}
+
+// confirm lists the projects an operation will change and asks the user to go ahead
+func confirm(in io.Reader, out io.Writer, projects []project.Project, question string) bool {
+	for _, p := range projects {
+		fmt.Fprintln(out, "  "+p.Name+" ("+p.ProjectID+")")
+	}
+	fmt.Fprint(out, question+"? [y/N] ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// printBulkResults prints the outcome of a bulk operation for each project and exits, with status 1 if any failed
+func printBulkResults(operation string, results []project.BulkResult) {
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(results)
+		fmt.Println(string(jsonResponse))
+	} else if len(results) == 0 {
+		fmt.Println("No projects match, nothing to " + operation)
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "PROJECT ID \tNAME \tSTATUS \tERROR")
+		for _, result := range results {
+			fmt.Fprintln(w, result.ProjectID+"\t"+result.Name+"\t"+result.Status+"\t"+result.Error)
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+	if project.BulkFailed(results) {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectLinkGraph : prints the links between the projects on a connection, as DOT or JSON
+func ProjectLinkGraph(c *cli.Context) {
+	conID := connectionIDFromFlags(c)
+	format := strings.TrimSpace(strings.ToLower(c.String("format")))
+	if printAsJSON {
+		format = "json"
+	}
+	if format != "dot" && format != "json" {
+		err := errors.New("format must be dot or json")
+		HandleProjectError(&project.ProjectError{Op: project.ErrOpInvalidOptions, Err: err, Desc: err.Error()})
+		os.Exit(1)
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	graph, projErr := project.BuildLinkGraph(http.DefaultClient, conInfo, conURL)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if format == "json" {
+		jsonResponse, _ := json.Marshal(graph)
+		fmt.Println(string(jsonResponse))
+	} else {
+		fmt.Print(graph.DOT())
+	}
+	os.Exit(0)
+}
+
+// ProjectReindex : rebuilds the index of which connection each project is bound to
+func ProjectReindex(c *cli.Context) {
+	result, projErr := project.ReindexProjects(http.DefaultClient)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(result)
+		fmt.Println(string(jsonResponse))
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "CONNECTION ID \tPROJECTS \tERROR")
+		for _, connection := range result.Connections {
+			fmt.Fprintln(w, connection.ConnectionID+"\t"+strconv.Itoa(connection.Projects)+"\t"+connection.Error)
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+		fmt.Println("Indexed " + strconv.Itoa(result.Projects) + " projects")
+	}
+	os.Exit(0)
+}
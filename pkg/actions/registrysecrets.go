@@ -117,7 +117,7 @@ func RemoveRegistrySecret(c *cli.Context) {
 }
 
 func getConnectionDetailsOrExit(c *cli.Context) (*connections.Connection, string) {
-	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	connectionID := connectionIDFromFlags(c)
 
 	conInfo, conInfoErr := connections.GetConnectionByID(connectionID)
 	if conInfoErr != nil {
@@ -165,7 +165,7 @@ func SecurityUserAddRole(c *cli.Context) {
 
 // SecurityKeyUpdate : Creates or updates a key in the platforms keyring
 func SecurityKeyUpdate(c *cli.Context) {
-	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	connectionID := connectionIDFromFlags(c)
 	username := strings.TrimSpace(strings.ToLower(c.String("username")))
 	password := strings.TrimSpace(c.String("password"))
 	err := security.SecKeyUpdate(connectionID, username, password)
@@ -180,7 +180,7 @@ func SecurityKeyUpdate(c *cli.Context) {
 
 // SecurityKeyValidate : Checks the key is available in the platform keyring
 func SecurityKeyValidate(c *cli.Context) {
-	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	connectionID := connectionIDFromFlags(c)
 	username := strings.TrimSpace(strings.ToLower(c.String("username")))
 	_, err := security.SecKeyGetSecret(connectionID, username)
 	if err != nil {
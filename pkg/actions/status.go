@@ -26,7 +26,7 @@ import (
 
 // StatusCommand : to show the status
 func StatusCommand(c *cli.Context) {
-	conID := c.String("conid")
+	conID := connectionIDFromFlags(c)
 	if conID != "" && conID != "local" {
 		StatusCommandRemoteConnection(c)
 	} else {
@@ -36,14 +36,19 @@ func StatusCommand(c *cli.Context) {
 
 // StatusCommandRemoteConnection : Output remote connection details
 func StatusCommandRemoteConnection(c *cli.Context) {
-	conID := c.String("conid")
+	conID := connectionIDFromFlags(c)
 	connection, conErr := connections.GetConnectionByID(conID)
 	if conErr != nil {
 		fmt.Println(conErr)
 		os.Exit(1)
 	}
 
-	PFEReady, err := apiroutes.IsPFEReady(http.DefaultClient, connection.URL)
+	httpClient, conErr := connections.ConnectionHTTPClient(http.DefaultClient, connection)
+	if conErr != nil {
+		fmt.Println(conErr)
+		os.Exit(1)
+	}
+	PFEReady, err := apiroutes.IsPFEReady(httpClient, connection.URL)
 	if err != nil || PFEReady == false {
 		if printAsJSON {
 			type status struct {
@@ -13,7 +13,6 @@ package actions
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
 	"github.com/eclipse/codewind-installer/pkg/templates"
@@ -25,7 +24,7 @@ import (
 // Filter them by providing flags
 func ListTemplates(c *cli.Context) {
 	projectStyle := c.String("projectStyle")
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	showEnabledOnly := c.Bool("showEnabledOnly")
 	templates, err := apiroutes.GetTemplates(conID, projectStyle, showEnabledOnly)
 	if err != nil {
@@ -42,7 +41,7 @@ func ListTemplates(c *cli.Context) {
 
 // ListTemplateStyles lists all template styles of which Codewind is aware.
 func ListTemplateStyles(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	styles, err := apiroutes.GetTemplateStyles(conID)
 	if err != nil {
 		templateErr := &TemplateError{errOpListStyles, err, err.Error()}
@@ -54,7 +53,7 @@ func ListTemplateStyles(c *cli.Context) {
 
 // ListTemplateRepos lists all template repos of which Codewind is aware.
 func ListTemplateRepos(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	repos, err := apiroutes.GetTemplateRepos(conID)
 	if err != nil {
 		templateErr := &TemplateError{errOpListRepos, err, err.Error()}
@@ -80,7 +79,7 @@ func AddTemplateRepo(c *cli.Context) {
 		return
 	}
 
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	repos, err := templates.AddTemplateRepo(conID, url, desc, name, gitCredentials)
 	if err != nil {
 		templateErr := &TemplateError{errOpAddRepo, err, err.Error()}
@@ -97,7 +96,7 @@ func AddTemplateRepo(c *cli.Context) {
 // DeleteTemplateRepo deletes the provided template repo from PFE.
 func DeleteTemplateRepo(c *cli.Context) {
 	url := c.String("url")
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	extensions, extensionsErr := apiroutes.GetExtensions(conID)
 	if extensionsErr == nil {
 		repos, reposErr := apiroutes.GetTemplateRepos(conID)
@@ -116,7 +115,7 @@ func DeleteTemplateRepo(c *cli.Context) {
 
 // EnableTemplateRepos enables templates repo of which Codewind is aware.
 func EnableTemplateRepos(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	repos, err := apiroutes.EnableTemplateRepos(conID, c.Args())
 	if err != nil {
 		templateErr := &TemplateError{errOpEnableRepo, err, err.Error()}
@@ -128,7 +127,7 @@ func EnableTemplateRepos(c *cli.Context) {
 
 // DisableTemplateRepos disables templates repo of which Codewind is aware.
 func DisableTemplateRepos(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connectionIDFromFlags(c)
 	repos, err := apiroutes.DisableTemplateRepos(conID, c.Args())
 	if err != nil {
 		templateErr := &TemplateError{errOpDisableRepo, err, err.Error()}
@@ -44,6 +44,16 @@ func HandleTemplateError(err *TemplateError) {
 	}
 }
 
+// HandleExtensionError prints an Extension error, in JSON format if the global flag is set, and as a string if not
+func HandleExtensionError(err *ExtensionError) {
+	// printAsJSON is a global variable, set in commands.go
+	if printAsJSON {
+		fmt.Println(err.Error())
+	} else {
+		logr.Error(err.Desc)
+	}
+}
+
 // HandleConnectionError prints a Connection error, in JSON format if the global flag is set and as a string if not
 func HandleConnectionError(err *connections.ConError) {
 	if printAsJSON {
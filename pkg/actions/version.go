@@ -15,7 +15,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/appconstants"
 	"github.com/eclipse/codewind-installer/pkg/config"
@@ -39,7 +38,7 @@ func GetVersions(c *cli.Context) {
 
 // GetSingleConnectionVersion : Prints the cwctl and container versions for a single connection to console
 func GetSingleConnectionVersion(c *cli.Context) {
-	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	connectionID := connectionIDFromFlags(c)
 
 	containerVersions, cvErr := GetContainerVersions(connectionID)
 	if cvErr != nil {
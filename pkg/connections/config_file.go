@@ -0,0 +1,251 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// A lock is a file created exclusively next to the file it protects, holding a token unique to the process
+// that created it. Any cwctl process changing the connections config holds its lock while it loads, changes
+// and saves the file. A lock older than staleLockAge was left behind by a process that died, as no change
+// takes that long.
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	staleLockAge      = 30 * time.Second
+)
+
+// lockConnectionsFile waits for the connections config lock and returns a function that releases it
+func lockConnectionsFile() (func(), *ConError) {
+	return LockFile(GetConnectionConfigFilename())
+}
+
+// LockFile : Waits for the lock of a config file that cwctl processes change concurrently, and returns
+// a function that releases it
+func LockFile(filename string) (func(), *ConError) {
+	lockPath := filename + ".lock"
+	os.MkdirAll(filepath.Dir(lockPath), 0777)
+	token, err := newLockToken()
+	if err != nil {
+		return nil, &ConError{errOpLock, err, err.Error()}
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = lockFile.WriteString(token)
+			closeErr := lockFile.Close()
+			if err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return nil, &ConError{errOpLock, err, err.Error()}
+			}
+			return func() { releaseLock(lockPath, token) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, &ConError{errOpLock, err, err.Error()}
+		}
+		if breakStaleLock(lockPath, token) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			err := errors.New(textLockTimeout + " " + lockPath)
+			return nil, &ConError{errOpLock, err, err.Error()}
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// newLockToken returns the process ID and a random suffix, which no other holder of the lock shares
+func newLockToken() (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return strconv.Itoa(os.Getpid()) + "-" + hex.EncodeToString(suffix), nil
+}
+
+// breakStaleLock removes the lock if it is stale, returning whether it did
+func breakStaleLock(lockPath string, token string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= staleLockAge {
+		return false
+	}
+	staleToken, err := ioutil.ReadFile(lockPath)
+	if err != nil || !removeLockHolding(lockPath, string(staleToken), token) {
+		return false
+	}
+	logr.Warnf("Removed stale lock %v", lockPath)
+	return true
+}
+
+// releaseLock removes the lock if this process still holds it. A lock broken as stale and
+// taken by another process is left for that process to release.
+func releaseLock(lockPath string, token string) {
+	if !removeLockHolding(lockPath, token, token) {
+		logr.Warnf("Lock %v is no longer held by this process, leaving it in place", lockPath)
+	}
+}
+
+// removeLockHolding removes the lock only if it holds the expected token. Checking the contents and then
+// removing the file would race with other processes replacing the lock in between, so the lock is first
+// renamed to a name only this process uses, which only one process can do, and checked once moved.
+// A lock that turns out to hold another token is put back, unless the lock has been taken again since.
+func removeLockHolding(lockPath string, expected string, token string) bool {
+	movedPath := lockPath + "." + token
+	if err := os.Rename(lockPath, movedPath); err != nil {
+		return false
+	}
+	defer os.Remove(movedPath)
+	moved, err := ioutil.ReadFile(movedPath)
+	if err == nil && string(moved) == expected {
+		return true
+	}
+	os.Link(movedPath, lockPath)
+	return false
+}
+
+// updateConnectionsConfig holds the connections config lock while it loads the config, applies the
+// update and saves the result. Nothing is saved if the update returns an error.
+func updateConnectionsConfig(update func(*ConnectionConfig) *ConError) *ConError {
+	unlock, conErr := lockConnectionsFile()
+	if conErr != nil {
+		return conErr
+	}
+	defer unlock()
+
+	data, conErr := loadOrRecoverConnectionsConfig()
+	if conErr != nil {
+		return conErr
+	}
+	conErr = update(data)
+	if conErr != nil {
+		return conErr
+	}
+	return writeConnectionsConfig(data)
+}
+
+// loadOrRecoverConnectionsConfig loads the connections config, recovering it if it is corrupt and
+// migrating it if it uses an older schema. The caller must hold the connections config lock.
+func loadOrRecoverConnectionsConfig() (*ConnectionConfig, *ConError) {
+	data, conErr := readConnectionsConfig(GetConnectionConfigFilename())
+	if conErr != nil && conErr.Op == errOpFileParse {
+		conErr = recoverConnectionsConfig(conErr)
+		if conErr != nil {
+			return nil, conErr
+		}
+		data, conErr = readConnectionsConfig(GetConnectionConfigFilename())
+	}
+	if conErr != nil || data.SchemaVersion == connectionsSchemaVersion {
+		return data, conErr
+	}
+	_, conErr = migrateConnectionsConfig(false)
+	if conErr != nil {
+		return nil, conErr
+	}
+	return readConnectionsConfig(GetConnectionConfigFilename())
+}
+
+// recoverConnectionsConfig replaces a corrupt connections config with its backup, or with the default
+// config when there is no usable backup. The corrupt file is kept alongside for inspection.
+func recoverConnectionsConfig(parseErr *ConError) *ConError {
+	filename := GetConnectionConfigFilename()
+	logr.Warnf("Connections config %v is corrupt: %v", filename, parseErr.Desc)
+	os.Rename(filename, filename+".corrupt")
+
+	if _, conErr := readConnectionsConfig(getConnectionsBackupFilename()); conErr == nil {
+		logr.Warnf("Restoring connections config from %v", getConnectionsBackupFilename())
+		backup, err := ioutil.ReadFile(getConnectionsBackupFilename())
+		if err == nil {
+			err = utils.WriteFileAtomically(filename, backup)
+		}
+		if err != nil {
+			return &ConError{errOpFileWrite, err, err.Error()}
+		}
+		return nil
+	}
+	logr.Warnf("No usable backup of the connections config, resetting it to the local connection")
+	return writeConnectionsConfig(defaultConnectionsConfig())
+}
+
+// readConnectionsConfig reads and parses a connections config file. An empty or unparsable file is
+// reported with the con_parse Op so it can be recovered, and a file with a newer schema is refused.
+func readConnectionsConfig(filename string) (*ConnectionConfig, *ConError) {
+	file, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, &ConError{errOpFileLoad, err, err.Error()}
+	}
+	data := ConnectionConfig{}
+	err = json.Unmarshal(file, &data)
+	if err == nil && data.Connections == nil && data.SchemaVersion == 0 {
+		err = errors.New(textEmptyConfig)
+	}
+	if err != nil {
+		return nil, &ConError{errOpFileParse, err, err.Error()}
+	}
+	if data.SchemaVersion > connectionsSchemaVersion {
+		return nil, newerSchemaError(data.SchemaVersion)
+	}
+	return &data, nil
+}
+
+// writeConnectionsConfig replaces the connections config, copying the previous version to the backup
+// file first. The new config is written to a temporary file that is renamed over the config, so no
+// reader ever sees a partly written file. The caller must hold the connections config lock.
+func writeConnectionsConfig(data interface{}) *ConError {
+	body, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return &ConError{errOpFileParse, err, err.Error()}
+	}
+	filename := GetConnectionConfigFilename()
+
+	// Only back up a config that can be read, so a corrupt file never replaces a good backup
+	if _, conErr := readConnectionsConfig(filename); conErr == nil {
+		previous, err := ioutil.ReadFile(filename)
+		if err == nil {
+			err = utils.WriteFileAtomically(getConnectionsBackupFilename(), previous)
+		}
+		if err != nil {
+			return &ConError{errOpFileWrite, err, err.Error()}
+		}
+	}
+
+	err = utils.WriteFileAtomically(filename, body)
+	if err != nil {
+		return &ConError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// getConnectionsLockFilename : get full file path of the connections config lock
+func getConnectionsLockFilename() string {
+	return GetConnectionConfigFilename() + ".lock"
+}
+
+// getConnectionsBackupFilename : get full file path of the backup of the previous connections config
+func getConnectionsBackupFilename() string {
+	return GetConnectionConfigFilename() + ".bak"
+}
@@ -0,0 +1,169 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useTempHome points the connections config at a temporary home directory, until the returned function is called
+func useTempHome(t *testing.T) func() {
+	home, err := ioutil.TempDir("", "cwctl-connections")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	os.MkdirAll(GetConnectionConfigDir(), 0777)
+	return func() {
+		os.Setenv("HOME", originalHome)
+		os.RemoveAll(home)
+	}
+}
+
+// writeTestConnectionsConfig replaces the connections config with data under the connections config lock
+func writeTestConnectionsConfig(t *testing.T, data *ConnectionConfig) {
+	unlock, conErr := lockConnectionsFile()
+	if conErr != nil {
+		t.Fatal(conErr)
+	}
+	defer unlock()
+	assert.Nil(t, writeConnectionsConfig(data))
+}
+
+func TestUpdateConnectionsConfigConcurrently(t *testing.T) {
+	defer useTempHome(t)()
+	assert.Nil(t, ResetConnectionsFile())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conErr := updateConnectionsConfig(func(data *ConnectionConfig) *ConError {
+				data.Connections = append(data.Connections, Connection{ID: "CON" + strconv.Itoa(i)})
+				return nil
+			})
+			assert.Nil(t, conErr)
+		}(i)
+	}
+	wg.Wait()
+
+	allConnections, conErr := GetAllConnections()
+	assert.Nil(t, conErr)
+	assert.Len(t, allConnections, 21)
+	_, err := os.Stat(getConnectionsLockFilename())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteConnectionsConfigKeepsBackup(t *testing.T) {
+	defer useTempHome(t)()
+	assert.Nil(t, ResetConnectionsFile())
+	conErr := updateConnectionsConfig(func(data *ConnectionConfig) *ConError {
+		data.Connections = append(data.Connections, Connection{ID: "REMOTE"})
+		return nil
+	})
+	assert.Nil(t, conErr)
+
+	backup, conErr := readConnectionsConfig(getConnectionsBackupFilename())
+	assert.Nil(t, conErr)
+	assert.Len(t, backup.Connections, 1)
+}
+
+func TestLoadCorruptConnectionsConfig(t *testing.T) {
+	tests := map[string]struct {
+		withBackup      bool
+		corruptContents string
+		wantConnections int
+	}{
+		"truncated file is restored from the backup": {
+			withBackup:      true,
+			corruptContents: `{"schemaversion": 1, "connections": [{"id": "loc`,
+			wantConnections: 2,
+		},
+		"empty file is restored from the backup": {
+			withBackup:      true,
+			corruptContents: "",
+			wantConnections: 2,
+		},
+		"corrupt file without a backup is reset": {
+			corruptContents: "not json",
+			wantConnections: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer useTempHome(t)()
+			assert.Nil(t, ResetConnectionsFile())
+			if test.withBackup {
+				data := defaultConnectionsConfig()
+				data.Connections = append(data.Connections, Connection{ID: "REMOTE"})
+				writeTestConnectionsConfig(t, data)
+				writeTestConnectionsConfig(t, data)
+			} else {
+				os.Remove(getConnectionsBackupFilename())
+			}
+			ioutil.WriteFile(GetConnectionConfigFilename(), []byte(test.corruptContents), 0644)
+
+			allConnections, conErr := GetAllConnections()
+			assert.Nil(t, conErr)
+			assert.Len(t, allConnections, test.wantConnections)
+			corrupt, err := ioutil.ReadFile(GetConnectionConfigFilename() + ".corrupt")
+			assert.Nil(t, err)
+			assert.Equal(t, test.corruptContents, string(corrupt))
+		})
+	}
+}
+
+func TestLockConnectionsFile(t *testing.T) {
+	t.Run("a stale lock is removed", func(t *testing.T) {
+		defer useTempHome(t)()
+		ioutil.WriteFile(getConnectionsLockFilename(), []byte("1"), 0644)
+		staleTime := time.Now().Add(-2 * staleLockAge)
+		os.Chtimes(getConnectionsLockFilename(), staleTime, staleTime)
+
+		unlock, conErr := lockConnectionsFile()
+		assert.Nil(t, conErr)
+		unlock()
+		_, err := os.Stat(getConnectionsLockFilename())
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("a lock taken by another process is not released", func(t *testing.T) {
+		defer useTempHome(t)()
+		unlock, conErr := lockConnectionsFile()
+		assert.Nil(t, conErr)
+		ioutil.WriteFile(getConnectionsLockFilename(), []byte("other"), 0644)
+
+		unlock()
+		owner, err := ioutil.ReadFile(getConnectionsLockFilename())
+		assert.Nil(t, err)
+		assert.Equal(t, "other", string(owner))
+		files, _ := ioutil.ReadDir(GetConnectionConfigDir())
+		assert.Len(t, files, 1)
+	})
+	t.Run("a lock that is not stale is not removed", func(t *testing.T) {
+		defer useTempHome(t)()
+		ioutil.WriteFile(getConnectionsLockFilename(), []byte("other"), 0644)
+
+		assert.False(t, breakStaleLock(getConnectionsLockFilename(), "token"))
+		owner, err := ioutil.ReadFile(getConnectionsLockFilename())
+		assert.Nil(t, err)
+		assert.Equal(t, "other", string(owner))
+	})
+}
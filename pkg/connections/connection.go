@@ -12,9 +12,7 @@
 package connections
 
 import (
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
@@ -45,25 +43,49 @@ type Connection struct {
 	Realm    string `json:"realm"`
 	ClientID string `json:"clientid"`
 	Username string `json:"username"`
+	// CACert is the path of a PEM encoded CA bundle trusted for the connection, in addition to the system CAs
+	CACert string `json:"cacert,omitempty"`
+	// CertFingerprint is the SHA-256 fingerprint the connection's certificate must have
+	CertFingerprint string `json:"certfingerprint,omitempty"`
+	// HTTPProxy, HTTPSProxy and SOCKSProxy are the proxies for requests to the connection, see ProxyFunc
+	HTTPProxy  string `json:"httpproxy,omitempty"`
+	HTTPSProxy string `json:"httpsproxy,omitempty"`
+	SOCKSProxy string `json:"socksproxy,omitempty"`
+	// NoProxy is a comma separated list of hosts and domains requested directly rather than through a proxy
+	NoProxy string `json:"noproxy,omitempty"`
 }
 
 const actionUpdateEntry = 0x01
 const actionAddEntry = 0x02
 
-// InitConfigFileIfRequired : Check the config file exist, if it does not then create a new default configuration
+// InitConfigFileIfRequired : Check the config file exist, if it does not then create a new default configuration.
+// A file with an older schema is migrated when it is first loaded.
 func InitConfigFileIfRequired() *ConError {
 	_, err := os.Stat(GetConnectionConfigFilename())
 	if os.IsNotExist(err) {
 		os.MkdirAll(GetConnectionConfigDir(), 0777)
 		return ResetConnectionsFile()
 	}
-	return applySchemaUpdates()
+	return nil
 }
 
 // ResetConnectionsFile : Creates a new / overwrites connection config file with a default single local Codewind connection
 func ResetConnectionsFile() *ConError {
-	// create the default local connection
-	initialConfig := ConnectionConfig{
+	unlock, conErr := lockConnectionsFile()
+	if conErr != nil {
+		return conErr
+	}
+	defer unlock()
+	conErr = clearDefaultConnection(GetDefaultConnectionID())
+	if conErr != nil {
+		return conErr
+	}
+	return writeConnectionsConfig(defaultConnectionsConfig())
+}
+
+// defaultConnectionsConfig : The connections config with a single local Codewind connection
+func defaultConnectionsConfig() *ConnectionConfig {
+	return &ConnectionConfig{
 		SchemaVersion: connectionsSchemaVersion,
 		Connections: []Connection{
 			Connection{
@@ -77,16 +99,6 @@ func ResetConnectionsFile() *ConError {
 			},
 		},
 	}
-	body, err := json.MarshalIndent(initialConfig, "", "\t")
-	if err != nil {
-		return &ConError{errOpFileParse, err, err.Error()}
-	}
-
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
-	if err != nil {
-		return &ConError{errOpFileWrite, err, err.Error()}
-	}
-	return nil
 }
 
 // GetConnectionByID : retrieve a single connection with matching ID
@@ -116,106 +128,113 @@ func GetConnectionsConfig() (*ConnectionConfig, *ConError) {
 // AddConnectionToList : validates then adds a new connection to the connection config
 func AddConnectionToList(httpClient utils.HTTPClient, c *cli.Context) (*Connection, *ConError) {
 	conID := strings.ToUpper(strconv.FormatInt(utils.CreateTimestamp(), 36))
-	label := strings.TrimSpace(c.String("label"))
-	url := strings.TrimSpace(c.String("url"))
-	username := strings.TrimSpace(c.String("username"))
-	conInfo, conErr := updateConnectionList(actionAddEntry, httpClient, conID, label, url, username)
+	conInfo, conErr := updateConnectionList(actionAddEntry, httpClient, conID, c)
 	return conInfo, conErr
 }
 
 // UpdateExistingConnection : Update an existing connection
 func UpdateExistingConnection(httpClient utils.HTTPClient, c *cli.Context) (*Connection, *ConError) {
 	conID := strings.ToUpper(c.String("conid"))
-	label := strings.TrimSpace(c.String("label"))
-	url := strings.TrimSpace(c.String("url"))
-	username := strings.TrimSpace(c.String("username"))
-	conInfo, conErr := updateConnectionList(actionUpdateEntry, httpClient, conID, label, url, username)
+	conInfo, conErr := updateConnectionList(actionUpdateEntry, httpClient, conID, c)
 	return conInfo, conErr
 }
 
 // updateConnectionList : validates then adds a new connection to the connection config
-func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID string, label string, url string, username string) (*Connection, *ConError) {
+func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID string, c *cli.Context) (*Connection, *ConError) {
 	if strings.EqualFold(connectionID, "LOCAL") {
 		err := errors.New("Local is a required connection that must not be modified")
 		return nil, &ConError{errOpProtected, err, err.Error()}
 	}
+	label := strings.TrimSpace(c.String("label"))
+	url := strings.TrimSpace(c.String("url"))
 	if url != "" && len(strings.TrimSpace(url)) > 0 {
 		url = strings.TrimSuffix(url, "/")
 	}
-	data, conErr := loadConnectionsConfigFile()
+	newConnection := Connection{
+		ID:       connectionID,
+		Label:    label,
+		URL:      url,
+		Username: strings.TrimSpace(c.String("username")),
+	}
+	conErr := newConnection.SetTrust(c.String("cacert"), c.String("fingerprint"))
 	if conErr != nil {
 		return nil, conErr
 	}
-
-	// check the url and label are not already in use
-	if action == actionAddEntry {
-		for i := 0; i < len(data.Connections); i++ {
-			if strings.EqualFold(label, data.Connections[i].Label) || strings.EqualFold(url, data.Connections[i].URL) {
-				conErr := errors.New("Connection ID: " + data.Connections[i].ID + " already exists. Use the update command to modify")
-				return nil, &ConError{errOpConflict, conErr, conErr.Error()}
-			}
-		}
+	conErr = newConnection.SetProxy(c.String("http-proxy"), c.String("https-proxy"), c.String("socks5-proxy"), c.String("no-proxy"))
+	if conErr != nil {
+		return nil, conErr
 	}
 
-	// check the connection already exists
-	if action == actionUpdateEntry {
-		connInfo, conErr := GetConnectionByID(connectionID)
-		if conErr != nil {
-			return nil, conErr
-		}
-		if connInfo == nil {
-			err := errors.New("Unable to update connection")
-			return nil, &ConError{errOpNotFound, err, err.Error()}
-		}
+	data, conErr := loadConnectionsConfigFile()
+	if conErr != nil {
+		return nil, conErr
+	}
+	conErr = checkConnectionListUpdate(action, data, connectionID, label, url)
+	if conErr != nil {
+		return nil, conErr
 	}
 
+	httpClient, conErr = ConnectionHTTPClient(httpClient, &newConnection)
+	if conErr != nil {
+		return nil, conErr
+	}
 	gatekeeperEnv, err := gatekeeper.GetGatekeeperEnvironment(httpClient, url)
 	if err != nil {
 		return nil, &ConError{errOpGetEnv, err, err.Error()}
 	}
+	newConnection.AuthURL = gatekeeperEnv.AuthURL
+	newConnection.Realm = gatekeeperEnv.Realm
+	newConnection.ClientID = gatekeeperEnv.ClientID
 
-	// create the new connection
-	newConnection := Connection{
-		ID:       connectionID,
-		Label:    label,
-		URL:      url,
-		AuthURL:  gatekeeperEnv.AuthURL,
-		Realm:    gatekeeperEnv.Realm,
-		ClientID: gatekeeperEnv.ClientID,
-		Username: username,
-	}
-
-	switch action {
-	case actionAddEntry:
-		{
-			data.Connections = append(data.Connections, newConnection)
-			break
+	// the file may have changed while the gatekeeper was contacted, so check again while holding the lock
+	conErr = updateConnectionsConfig(func(data *ConnectionConfig) *ConError {
+		conErr := checkConnectionListUpdate(action, data, connectionID, label, url)
+		if conErr != nil {
+			return conErr
 		}
-	case actionUpdateEntry:
-		{
+		switch action {
+		case actionAddEntry:
+			data.Connections = append(data.Connections, newConnection)
+		case actionUpdateEntry:
 			for i := 0; i < len(data.Connections); i++ {
-				connection := data.Connections[i]
-				if strings.ToUpper(connection.ID) == strings.ToUpper(connectionID) {
+				if strings.EqualFold(data.Connections[i].ID, connectionID) {
 					data.Connections[i] = newConnection
 					break
 				}
 			}
-			break
 		}
+		return nil
+	})
+	if conErr != nil {
+		return nil, conErr
 	}
+	return &newConnection, nil
+}
 
-	// Write list
-	body, err := json.MarshalIndent(data, "", "\t")
-	if err != nil {
-		return nil, &ConError{errOpFileParse, err, err.Error()}
+// checkConnectionListUpdate : checks a new connection does not reuse the label or url of an existing one,
+// and that a connection being updated exists
+func checkConnectionListUpdate(action int, data *ConnectionConfig, connectionID string, label string, url string) *ConError {
+	// check the url and label are not already in use
+	if action == actionAddEntry {
+		for i := 0; i < len(data.Connections); i++ {
+			if strings.EqualFold(label, data.Connections[i].Label) || strings.EqualFold(url, data.Connections[i].URL) {
+				conErr := errors.New("Connection ID: " + data.Connections[i].ID + " already exists. Use the update command to modify")
+				return &ConError{errOpConflict, conErr, conErr.Error()}
+			}
+		}
 	}
 
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
-	if err != nil {
-		return nil, &ConError{errOpFileWrite, err, err.Error()}
+	// check the connection already exists
+	if action == actionUpdateEntry {
+		for i := 0; i < len(data.Connections); i++ {
+			if strings.EqualFold(data.Connections[i].ID, connectionID) {
+				return nil
+			}
+		}
+		err := errors.New("Connection " + strings.ToUpper(connectionID) + " not found")
+		return &ConError{errOpNotFound, err, err.Error()}
 	}
-
-	return &newConnection, nil
+	return nil
 }
 
 // RemoveConnectionFromList : Removes the stored entry
@@ -233,27 +252,15 @@ func RemoveConnectionFromList(c *cli.Context) *ConError {
 		return conErr
 	}
 
-	data, conErr := loadConnectionsConfigFile()
-	if conErr != nil {
-		return conErr
-	}
-
-	for i := 0; i < len(data.Connections); i++ {
-		if strings.EqualFold(id, data.Connections[i].ID) {
-			copy(data.Connections[i:], data.Connections[i+1:])
-			data.Connections = data.Connections[:len(data.Connections)-1]
+	return updateConnectionsConfig(func(data *ConnectionConfig) *ConError {
+		for i := 0; i < len(data.Connections); i++ {
+			if strings.EqualFold(id, data.Connections[i].ID) {
+				copy(data.Connections[i:], data.Connections[i+1:])
+				data.Connections = data.Connections[:len(data.Connections)-1]
+			}
 		}
-	}
-	body, err := json.MarshalIndent(data, "", "\t")
-	if err != nil {
-		return &ConError{errOpFileParse, err, err.Error()}
-	}
-
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
-	if err != nil {
-		return &ConError{errOpFileWrite, err, err.Error()}
-	}
-	return nil
+		return clearDefaultConnection(id)
+	})
 }
 
 // GetAllConnections : Retrieve all saved connections
@@ -270,32 +277,23 @@ func GetAllConnections() ([]Connection, *ConError) {
 }
 
 // loadConnectionsConfigFile : Load the connections configuration file from disk
-// and returns the contents of the file or an error
+// and returns the contents of the file or an error. A corrupt file is recovered
+// from its backup, and a file with an older schema is migrated.
 func loadConnectionsConfigFile() (*ConnectionConfig, *ConError) {
-	file, err := ioutil.ReadFile(GetConnectionConfigFilename())
-	if err != nil {
-		return nil, &ConError{errOpFileLoad, err, err.Error()}
-	}
-	data := ConnectionConfig{}
-	err = json.Unmarshal([]byte(file), &data)
-	if err != nil {
-		return nil, &ConError{errOpFileParse, err, err.Error()}
+	data, conErr := readConnectionsConfig(GetConnectionConfigFilename())
+	if conErr == nil && data.SchemaVersion == connectionsSchemaVersion {
+		return data, nil
 	}
-	return &data, nil
-}
-
-// saveConnectionsConfigFile : Save the connections configuration file to disk
-// returns an error, and error code
-func saveConnectionsConfigFile(ConnectionConfig *ConnectionConfig) *ConError {
-	body, err := json.MarshalIndent(ConnectionConfig, "", "\t")
-	if err != nil {
-		return &ConError{errOpFileParse, err, err.Error()}
+	if conErr != nil && conErr.Op != errOpFileParse {
+		return nil, conErr
 	}
-	conErr := ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
-	if conErr != nil {
-		return &ConError{errOpFileWrite, conErr, conErr.Error()}
+	unlock, lockErr := lockConnectionsFile()
+	if lockErr != nil {
+		return nil, lockErr
 	}
-	return nil
+	defer unlock()
+	// another process may have recovered or migrated the file while we waited for the lock
+	return loadOrRecoverConnectionsConfig()
 }
 
 // GetConnectionConfigDir : get path to the connections config directory
@@ -325,72 +323,3 @@ func GetConnectionConfigDir() string {
 func GetConnectionConfigFilename() string {
 	return path.Join(GetConnectionConfigDir(), "connections.json")
 }
-
-func loadRawConnectionsFile() ([]byte, *ConError) {
-	file, err := ioutil.ReadFile(GetConnectionConfigFilename())
-	if err != nil {
-		return nil, &ConError{errOpFileLoad, err, err.Error()}
-	}
-	return file, nil
-}
-
-// applySchemaUpdates : update any existing entries to use the new schema design
-func applySchemaUpdates() *ConError {
-
-	loadedFile, conErr := loadConnectionsConfigFile()
-	if conErr != nil {
-		return conErr
-	}
-	savedSchemaVersion := loadedFile.SchemaVersion
-
-	// upgrade the schema if needed
-	if savedSchemaVersion < connectionsSchemaVersion {
-		file, conErr := loadRawConnectionsFile()
-		if conErr != nil {
-			return conErr
-		}
-
-		// apply schama updates from version 0 to version 1
-		if savedSchemaVersion == 0 {
-
-			// current config file
-			ConnectionConfig := ConnectionConfigV0{}
-
-			// create new config structure
-			newConnectionConfig := ConnectionConfigV1{}
-
-			err := json.Unmarshal([]byte(file), &ConnectionConfig)
-			if err != nil {
-				return &ConError{errOpFileParse, err, err.Error()}
-			}
-
-			newConnectionConfig.SchemaVersion = 1
-
-			// copy connections from old to new config
-			originalConnectionsV0 := ConnectionConfig.Connections
-			for i := 0; i < len(originalConnectionsV0); i++ {
-				originalConnection := originalConnectionsV0[i]
-				connectionJSON, _ := json.Marshal(originalConnection)
-				var upgradedConnection ConnectionV1
-				err = json.Unmarshal(connectionJSON, &upgradedConnection)
-
-				if err == nil {
-					// rename 'name' field to 'id'
-					upgradedConnection.ID = originalConnection.Name
-					newConnectionConfig.Connections = append(newConnectionConfig.Connections, upgradedConnection)
-				}
-			}
-
-			// schema has been updated
-			body, err := json.MarshalIndent(newConnectionConfig, "", "\t")
-			if err != nil {
-				return &ConError{errOpFileParse, err, err.Error()}
-			}
-			err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
-			if err != nil {
-				return &ConError{errOpFileWrite, err, err.Error()}
-			}
-		}
-	}
-	return nil
-}
@@ -31,10 +31,32 @@ const (
 	errOpNotFound     = "con_not_found"
 	errOpProtected    = "con_protected"
 	errOpGetEnv       = "con_environment"
+	errOpLock         = "con_lock"
+	errOpImport       = "con_import"
+	errOpTrust        = "con_trust"
+	errOpProxy        = "con_proxy"
 )
 
 const (
 	errTargetNotFound = "Target connection not found"
+	textLockTimeout   = "Timed out waiting for the connections config lock, remove it if no other cwctl is running:"
+	textEmptyConfig   = "connections config is empty"
+
+	textExportLocal           = "The local connection exists on every machine and cannot be exported"
+	textImportLocal           = "The local connection cannot be imported, only connections with a URL"
+	textExportVersion         = "Connections export file is from a newer version of cwctl, export version"
+	textInvalidConflictPolicy = "Conflict policy must be skip, overwrite or rename"
+	textImportChanged         = "The connections list changed during the import, import this connection again"
+
+	textNewerSchema = "Connections config was written by a newer version of cwctl, upgrade cwctl to use it"
+	textNoMigration = "No migration is registered from connections schema version"
+
+	textInvalidCACert       = "No PEM encoded certificates found in CA bundle"
+	textInvalidFingerprint  = "Certificate fingerprint must be a SHA-256 fingerprint of 64 hex digits"
+	textFingerprintMismatch = "Server certificate does not match the pinned fingerprint, its fingerprint is"
+	textNoPeerCertificate   = "Server did not present a certificate"
+
+	textInvalidProxy = "Invalid proxy URL"
 )
 
 // ConError : Error formatted in JSON containing an errorOp and a description from
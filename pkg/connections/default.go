@@ -0,0 +1,110 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// DefaultConnectionEnv : Environment variable naming the connection to use when a command is not given --conid
+const DefaultConnectionEnv = "CWCTL_CONNECTION"
+
+// defaultConnectionFile : The connection chosen with connections use, kept beside the connections config
+type defaultConnectionFile struct {
+	ConnectionID string `json:"connectionID"`
+}
+
+// ResolveConnectionID : The connection a command should use. This is the given --conid if there is one, then the
+// connection named by CWCTL_CONNECTION, then the connection chosen with connections use, and finally local.
+func ResolveConnectionID(conID string) string {
+	conID = strings.TrimSpace(strings.ToLower(conID))
+	if conID != "" {
+		return conID
+	}
+	if envConID := strings.TrimSpace(strings.ToLower(os.Getenv(DefaultConnectionEnv))); envConID != "" {
+		return envConID
+	}
+	if defaultConID := GetDefaultConnectionID(); defaultConID != "" {
+		return defaultConID
+	}
+	return "local"
+}
+
+// GetDefaultConnectionID : The connection chosen with connections use, or an empty string if none has been chosen
+func GetDefaultConnectionID() string {
+	data, err := ioutil.ReadFile(getDefaultConnectionFilename())
+	if err != nil {
+		return ""
+	}
+	defaultConnection := defaultConnectionFile{}
+	if json.Unmarshal(data, &defaultConnection) != nil {
+		return ""
+	}
+	return strings.ToLower(defaultConnection.ConnectionID)
+}
+
+// SetDefaultConnection : Makes a connection the one used by commands that are not given --conid
+func SetDefaultConnection(conID string) (*Connection, *ConError) {
+	connection, conErr := GetConnectionByID(conID)
+	if conErr != nil {
+		return nil, conErr
+	}
+	unlock, conErr := lockConnectionsFile()
+	if conErr != nil {
+		return nil, conErr
+	}
+	defer unlock()
+
+	conErr = writeDefaultConnection(strings.ToLower(connection.ID))
+	if conErr != nil {
+		return nil, conErr
+	}
+	return connection, nil
+}
+
+// clearDefaultConnection : Forgets the chosen connection if it is the given connection, so commands go back
+// to using local. The caller must hold the connections config lock.
+func clearDefaultConnection(conID string) *ConError {
+	defaultConID := GetDefaultConnectionID()
+	if defaultConID == "" || !strings.EqualFold(defaultConID, conID) {
+		return nil
+	}
+	err := os.Remove(getDefaultConnectionFilename())
+	if err != nil && !os.IsNotExist(err) {
+		return &ConError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// writeDefaultConnection : Saves the chosen connection. The caller must hold the connections config lock.
+func writeDefaultConnection(conID string) *ConError {
+	body, err := json.MarshalIndent(defaultConnectionFile{ConnectionID: conID}, "", "\t")
+	if err != nil {
+		return &ConError{errOpFileParse, err, err.Error()}
+	}
+	err = utils.WriteFileAtomically(getDefaultConnectionFilename(), body)
+	if err != nil {
+		return &ConError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// getDefaultConnectionFilename : get full file path of the chosen connection file
+func getDefaultConnectionFilename() string {
+	return path.Join(GetConnectionConfigDir(), "default-connection.json")
+}
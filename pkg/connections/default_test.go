@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestResolveConnectionID(t *testing.T) {
+	defer useTempHome(t)()
+	originalEnv, envSet := os.LookupEnv(DefaultConnectionEnv)
+	defer func() {
+		if envSet {
+			os.Setenv(DefaultConnectionEnv, originalEnv)
+		} else {
+			os.Unsetenv(DefaultConnectionEnv)
+		}
+	}()
+	os.Unsetenv(DefaultConnectionEnv)
+
+	data := defaultConnectionsConfig()
+	data.Connections = append(data.Connections, Connection{ID: "REMOTE1"}, Connection{ID: "REMOTE2"})
+	writeTestConnectionsConfig(t, data)
+
+	t.Run("defaults to local", func(t *testing.T) {
+		assert.Equal(t, "local", ResolveConnectionID(""))
+	})
+	t.Run("uses the connection chosen with connections use", func(t *testing.T) {
+		_, conErr := SetDefaultConnection("remote1")
+		assert.Nil(t, conErr)
+		assert.Equal(t, "remote1", ResolveConnectionID(""))
+	})
+	t.Run("the environment overrides the chosen connection", func(t *testing.T) {
+		os.Setenv(DefaultConnectionEnv, "REMOTE2")
+		defer os.Unsetenv(DefaultConnectionEnv)
+		assert.Equal(t, "remote2", ResolveConnectionID(""))
+	})
+	t.Run("the flag overrides everything", func(t *testing.T) {
+		os.Setenv(DefaultConnectionEnv, "REMOTE2")
+		defer os.Unsetenv(DefaultConnectionEnv)
+		assert.Equal(t, "local", ResolveConnectionID(" LOCAL "))
+	})
+	t.Run("an unknown connection cannot be chosen", func(t *testing.T) {
+		_, conErr := SetDefaultConnection("missing")
+		assert.Equal(t, errOpNotFound, conErr.Op)
+		assert.Equal(t, "remote1", GetDefaultConnectionID())
+	})
+	t.Run("removing the chosen connection forgets it", func(t *testing.T) {
+		set := flag.NewFlagSet("tests", 0)
+		set.String("conid", "REMOTE1", "doc")
+		assert.Nil(t, RemoveConnectionFromList(cli.NewContext(nil, set, nil)))
+		assert.Equal(t, "", GetDefaultConnectionID())
+		assert.Equal(t, "local", ResolveConnectionID(""))
+	})
+}
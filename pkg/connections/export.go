@@ -0,0 +1,368 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/gatekeeper"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ConnectionsExport : A shareable list of connections. It holds no secrets, and the authentication
+	// settings of each connection are read from its gatekeeper when it is imported. The trust and proxy
+	// settings needed to reach each gatekeeper are included, with the contents of its CA bundle in place of its path.
+	ConnectionsExport struct {
+		ExportVersion int                  `json:"exportversion"`
+		Connections   []ExportedConnection `json:"connections"`
+	}
+
+	// ExportedConnection : A connection as it is shared with others
+	ExportedConnection struct {
+		ID       string `json:"id"`
+		Label    string `json:"label"`
+		URL      string `json:"url"`
+		Username string `json:"username"`
+		// CACert is the PEM encoded CA bundle trusted for the connection
+		CACert          string `json:"cacert,omitempty"`
+		CertFingerprint string `json:"certfingerprint,omitempty"`
+		// The proxies for the connection, without any credentials
+		HTTPProxy  string `json:"httpproxy,omitempty"`
+		HTTPSProxy string `json:"httpsproxy,omitempty"`
+		SOCKSProxy string `json:"socksproxy,omitempty"`
+		NoProxy    string `json:"noproxy,omitempty"`
+	}
+
+	// ImportResult : What happened to one imported connection
+	ImportResult struct {
+		ID     string `json:"id,omitempty"`
+		Label  string `json:"label"`
+		URL    string `json:"url"`
+		Status string `json:"status"`
+		Detail string `json:"detail,omitempty"`
+	}
+)
+
+// connectionsExportVersion must be incremented when changing the ConnectionsExport format
+const connectionsExportVersion = 2
+
+// Conflict policies for imported connections with the label or URL of an existing connection
+const (
+	ImportSkip      = "skip"
+	ImportOverwrite = "overwrite"
+	ImportRename    = "rename"
+)
+
+// Statuses of imported connections
+const (
+	importAdded       = "added"
+	importOverwritten = "overwritten"
+	importRenamed     = "renamed"
+	importSkipped     = "skipped"
+	importFailed      = "failed"
+)
+
+// ExportConnections : Returns the connections with the given IDs, or all remote connections if no IDs are given,
+// without their secrets. The local connection exists everywhere so is never exported.
+func ExportConnections(ids []string) (*ConnectionsExport, *ConError) {
+	allConnections, conErr := GetAllConnections()
+	if conErr != nil {
+		return nil, conErr
+	}
+	export := &ConnectionsExport{ExportVersion: connectionsExportVersion, Connections: []ExportedConnection{}}
+	if len(ids) == 0 {
+		for _, connection := range allConnections {
+			if !strings.EqualFold(connection.ID, "local") {
+				exported, conErr := exportConnection(connection)
+				if conErr != nil {
+					return nil, conErr
+				}
+				export.Connections = append(export.Connections, exported)
+			}
+		}
+		return export, nil
+	}
+
+	for _, id := range ids {
+		if strings.EqualFold(id, "local") {
+			err := errors.New(textExportLocal)
+			return nil, &ConError{errOpImport, err, err.Error()}
+		}
+		connection, conErr := GetConnectionByID(id)
+		if conErr != nil {
+			return nil, conErr
+		}
+		exported, conErr := exportConnection(*connection)
+		if conErr != nil {
+			return nil, conErr
+		}
+		export.Connections = append(export.Connections, exported)
+	}
+	return export, nil
+}
+
+// exportConnection : The shareable fields of a connection
+func exportConnection(connection Connection) (ExportedConnection, *ConError) {
+	exported := ExportedConnection{
+		ID:              connection.ID,
+		Label:           connection.Label,
+		URL:             connection.URL,
+		Username:        connection.Username,
+		CertFingerprint: connection.CertFingerprint,
+		HTTPProxy:       withoutCredentials(connection.HTTPProxy),
+		HTTPSProxy:      withoutCredentials(connection.HTTPSProxy),
+		SOCKSProxy:      withoutCredentials(connection.SOCKSProxy),
+		NoProxy:         connection.NoProxy,
+	}
+	if connection.CACert != "" {
+		pem, err := ioutil.ReadFile(connection.CACert)
+		if err != nil {
+			return ExportedConnection{}, &ConError{errOpTrust, err, err.Error()}
+		}
+		exported.CACert = string(pem)
+	}
+	return exported, nil
+}
+
+// withoutCredentials : A proxy URL without any user name and password
+func withoutCredentials(proxy string) string {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.User == nil {
+		return proxy
+	}
+	proxyURL.User = nil
+	return proxyURL.String()
+}
+
+// importedConnection : A connection with the fields of an exported connection, saving its CA bundle
+// in the connections config directory
+func importedConnection(imported ExportedConnection, label, url string) (Connection, *ConError) {
+	connection := Connection{Label: label, URL: url, Username: strings.TrimSpace(imported.Username)}
+	caCert := ""
+	if strings.TrimSpace(imported.CACert) != "" {
+		sum := sha256.Sum256([]byte(imported.CACert))
+		caCert = filepath.Join(GetConnectionConfigDir(), "certs", hex.EncodeToString(sum[:8])+".pem")
+		err := os.MkdirAll(filepath.Dir(caCert), 0700)
+		if err == nil {
+			err = ioutil.WriteFile(caCert, []byte(imported.CACert), 0600)
+		}
+		if err != nil {
+			return connection, &ConError{errOpTrust, err, err.Error()}
+		}
+	}
+	if conErr := connection.SetTrust(caCert, imported.CertFingerprint); conErr != nil {
+		return connection, conErr
+	}
+	conErr := connection.SetProxy(imported.HTTPProxy, imported.HTTPSProxy, imported.SOCKSProxy, imported.NoProxy)
+	return connection, conErr
+}
+
+// ParseConnectionsExport : Reads an export file, refusing versions newer than this cwctl understands
+func ParseConnectionsExport(body []byte) (*ConnectionsExport, *ConError) {
+	export := ConnectionsExport{}
+	err := json.Unmarshal(body, &export)
+	if err != nil {
+		return nil, &ConError{errOpFileParse, err, err.Error()}
+	}
+	if export.ExportVersion > connectionsExportVersion {
+		err := errors.New(textExportVersion + " " + strconv.Itoa(export.ExportVersion))
+		return nil, &ConError{errOpImport, err, err.Error()}
+	}
+	return &export, nil
+}
+
+// ImportConnections : Adds exported connections to the connection config. Each URL is checked with its gatekeeper,
+// as connections add does, using the connection's trust and proxy settings, unless the connection is going to be skipped. An imported connection whose label or URL
+// is already in use is handled using the conflict policy. A connection is only renamed for a clashing label; one whose
+// URL is in use is already configured so is skipped unless the policy is overwrite.
+func ImportConnections(httpClient utils.HTTPClient, export *ConnectionsExport, policy string) ([]ImportResult, *ConError) {
+	if policy != ImportSkip && policy != ImportOverwrite && policy != ImportRename {
+		err := errors.New(textInvalidConflictPolicy + ": " + policy)
+		return nil, &ConError{errOpImport, err, err.Error()}
+	}
+
+	existing, conErr := loadConnectionsConfigFile()
+	if conErr != nil {
+		return nil, conErr
+	}
+
+	// Ask each gatekeeper for its environment before locking the config, as this may take a while.
+	// A connection that is going to be skipped is not checked.
+	candidates := make([]Connection, len(export.Connections))
+	environments := make([]*gatekeeper.GatekeeperEnvironment, len(export.Connections))
+	envErrors := make([]error, len(export.Connections))
+	for i, imported := range export.Connections {
+		label := strings.TrimSpace(imported.Label)
+		url := strings.TrimSuffix(strings.TrimSpace(imported.URL), "/")
+		candidates[i] = Connection{Label: label, URL: url}
+		if strings.EqualFold(imported.ID, "local") || url == "" {
+			envErrors[i] = errors.New(textImportLocal)
+			continue
+		}
+		conflict := findConflictingConnection(existing, label, url)
+		if policy == ImportSkip && conflict >= 0 {
+			continue
+		}
+		candidate, conErr := importedConnection(imported, label, url)
+		if conErr != nil {
+			envErrors[i] = conErr
+			continue
+		}
+		if policy == ImportOverwrite && conflict >= 0 {
+			keepLocalSettings(&candidate, existing.Connections[conflict])
+		}
+		candidates[i] = candidate
+		connectionClient, conErr := ConnectionHTTPClient(httpClient, &candidate)
+		if conErr != nil {
+			envErrors[i] = conErr
+			continue
+		}
+		environments[i], envErrors[i] = gatekeeper.GetGatekeeperEnvironment(connectionClient, url)
+	}
+
+	results := []ImportResult{}
+	conErr = updateConnectionsConfig(func(data *ConnectionConfig) *ConError {
+		nextID := utils.CreateTimestamp()
+		for i := range export.Connections {
+			newConnection := candidates[i]
+			label, url := newConnection.Label, newConnection.URL
+			result := ImportResult{Label: label, URL: url}
+			conflict := findConflictingConnection(data, label, url)
+			env := environments[i]
+			if envErrors[i] == nil && env == nil && conflict < 0 {
+				// the conflicting connection was removed after the gatekeeper environments were fetched
+				envErrors[i] = errors.New(textImportChanged)
+			}
+			if envErrors[i] != nil {
+				result.Status, result.Detail = importFailed, envErrors[i].Error()
+				results = append(results, result)
+				continue
+			}
+			if env != nil {
+				newConnection.AuthURL = env.AuthURL
+				newConnection.Realm = env.Realm
+				newConnection.ClientID = env.ClientID
+			}
+
+			switch {
+			case conflict < 0:
+				newConnection.ID, nextID = newConnectionID(data, nextID)
+				data.Connections = append(data.Connections, newConnection)
+				result.Status = importAdded
+			case strings.EqualFold(data.Connections[conflict].ID, "local"):
+				result.Status, result.Detail = importSkipped, "conflicts with the local connection"
+			case policy == ImportOverwrite:
+				keepLocalSettings(&newConnection, data.Connections[conflict])
+				newConnection.ID = data.Connections[conflict].ID
+				data.Connections[conflict] = newConnection
+				result.Status = importOverwritten
+			case policy == ImportRename && !connectionURLInUse(data, url):
+				newConnection.Label = uniqueLabel(data, label)
+				newConnection.ID, nextID = newConnectionID(data, nextID)
+				data.Connections = append(data.Connections, newConnection)
+				result.Status, result.Detail = importRenamed, "label "+label+" is used by connection "+data.Connections[conflict].ID
+			default:
+				result.Status, result.Detail = importSkipped, "conflicts with connection "+data.Connections[conflict].ID
+			}
+			result.ID, result.Label = newConnection.ID, newConnection.Label
+			if result.Status == importSkipped {
+				result.ID = ""
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if conErr != nil {
+		return nil, conErr
+	}
+	return results, nil
+}
+
+// keepLocalSettings : Copies the trust and proxy settings of an existing connection to the connection overwriting it,
+// where the imported connection has none of its own
+func keepLocalSettings(connection *Connection, existing Connection) {
+	if connection.CACert == "" && connection.CertFingerprint == "" {
+		connection.CACert = existing.CACert
+		connection.CertFingerprint = existing.CertFingerprint
+	}
+	if !connection.hasProxy() {
+		connection.HTTPProxy = existing.HTTPProxy
+		connection.HTTPSProxy = existing.HTTPSProxy
+		connection.SOCKSProxy = existing.SOCKSProxy
+		connection.NoProxy = existing.NoProxy
+	}
+}
+
+// findConflictingConnection : The index of the connection using the label or URL, or -1 if neither is in use
+func findConflictingConnection(data *ConnectionConfig, label, url string) int {
+	for i, connection := range data.Connections {
+		if strings.EqualFold(label, connection.Label) || strings.EqualFold(url, connection.URL) {
+			return i
+		}
+	}
+	return -1
+}
+
+// connectionURLInUse : Whether a connection with the URL exists
+func connectionURLInUse(data *ConnectionConfig, url string) bool {
+	for _, connection := range data.Connections {
+		if strings.EqualFold(url, connection.URL) {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueLabel : The label with the lowest number appended that no connection uses
+func uniqueLabel(data *ConnectionConfig, label string) string {
+	for n := 2; ; n++ {
+		candidate := label + " (" + strconv.Itoa(n) + ")"
+		inUse := false
+		for _, connection := range data.Connections {
+			if strings.EqualFold(connection.Label, candidate) {
+				inUse = true
+				break
+			}
+		}
+		if !inUse {
+			return candidate
+		}
+	}
+}
+
+// newConnectionID : A connection ID made from a timestamp that no connection uses, and the timestamp to try next
+func newConnectionID(data *ConnectionConfig, timestamp int64) (string, int64) {
+	for {
+		id := strings.ToUpper(strconv.FormatInt(timestamp, 36))
+		timestamp++
+		inUse := false
+		for _, connection := range data.Connections {
+			if strings.EqualFold(connection.ID, id) {
+				inUse = true
+				break
+			}
+		}
+		if !inUse {
+			return id, timestamp
+		}
+	}
+}
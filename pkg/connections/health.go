@@ -0,0 +1,250 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/gatekeeper"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ConnectionCheck : The result of one health check of a connection and how long it took
+	ConnectionCheck struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Detail     string `json:"detail,omitempty"`
+		DurationMs int64  `json:"durationMs"`
+	}
+
+	// HealthCheckOptions : What the health checks of a connection need that is looked up outside this package,
+	// as the keyring and the Codewind server of the local connection are managed by packages that import this one
+	HealthCheckOptions struct {
+		// PFEURL is the URL of the connection's Codewind server, or PFEURLError why it could not be found
+		PFEURL       string
+		PFEURLError  string
+		AccessToken  string
+		RefreshToken string
+		Timeout      time.Duration
+	}
+)
+
+// Health check names and statuses. A warning does not make a connection unhealthy.
+const (
+	CheckGatekeeper = "gatekeeper"
+	CheckTLS        = "tls"
+	CheckToken      = "token"
+	CheckPFEReady   = "pfe"
+	CheckVersions   = "versions"
+
+	CheckOK      = "ok"
+	CheckWarning = "warning"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// CheckConnection : Runs the gatekeeper, TLS, token and Codewind server health checks against a connection.
+// The gatekeeper, TLS and token checks are skipped for the local connection, which has none of them.
+func CheckConnection(httpClient utils.HTTPClient, connection *Connection, options HealthCheckOptions) []ConnectionCheck {
+	checks := []ConnectionCheck{}
+	isLocal := strings.EqualFold(connection.ID, "local")
+	tlsConfig, trustErr := connection.TLSConfig()
+	if trustErr == nil {
+		httpClient, trustErr = ConnectionHTTPClient(httpClient, connection)
+	}
+
+	checks = append(checks, RunConnectionCheck(CheckGatekeeper, func() (string, string) {
+		if isLocal {
+			return CheckSkipped, ""
+		}
+		env, err := gatekeeper.GetGatekeeperEnvironment(httpClient, connection.URL)
+		if err != nil {
+			return CheckFailed, err.Error()
+		}
+		return CheckOK, "realm " + env.Realm
+	}))
+
+	checks = append(checks, RunConnectionCheck(CheckTLS, func() (string, string) {
+		if isLocal {
+			return CheckSkipped, ""
+		}
+		if trustErr != nil {
+			return CheckFailed, trustErr.Desc
+		}
+		return CheckCertificate(connection.URL, tlsConfig, connection.ProxyFunc(), options.Timeout)
+	}))
+
+	checks = append(checks, RunConnectionCheck(CheckToken, func() (string, string) {
+		if isLocal {
+			return CheckSkipped, ""
+		}
+		return AccessTokenStatus(options.AccessToken, options.RefreshToken, time.Now())
+	}))
+
+	checks = append(checks, RunConnectionCheck(CheckPFEReady, func() (string, string) {
+		if options.PFEURLError != "" {
+			return CheckFailed, options.PFEURLError
+		}
+		if isLocal {
+			return pfeReadyStatus(httpClient, options.PFEURL, "")
+		}
+		return pfeReadyStatus(withoutRedirects(httpClient), options.PFEURL, options.AccessToken)
+	}))
+	return checks
+}
+
+// RunConnectionCheck : Runs a check and records how long it took
+func RunConnectionCheck(name string, check func() (string, string)) ConnectionCheck {
+	start := time.Now()
+	status, detail := check()
+	return ConnectionCheck{
+		Name:       name,
+		Status:     status,
+		Detail:     detail,
+		DurationMs: int64(time.Since(start) / time.Millisecond),
+	}
+}
+
+// pfeReadyStatus asks a Codewind server whether it is ready, sending the access token when there is one.
+// The gatekeeper of a remote connection redirects requests without a valid token to Keycloak, so a
+// redirect is reported as a failure rather than followed to the Keycloak login page.
+func pfeReadyStatus(httpClient utils.HTTPClient, pfeURL string, accessToken string) (string, string) {
+	req, err := http.NewRequest("GET", pfeURL+"/ready", nil)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return CheckOK, ""
+	case res.StatusCode >= 300 && res.StatusCode < 400, res.StatusCode == http.StatusUnauthorized:
+		return CheckFailed, "the gatekeeper did not accept the access token, log in with cwctl sectoken get"
+	}
+	return CheckFailed, "Codewind is not ready, status " + strconv.Itoa(res.StatusCode)
+}
+
+// withoutRedirects returns a copy of an *http.Client that returns redirects instead of following them.
+// Any other client, such as a mock, is returned unchanged.
+func withoutRedirects(httpClient utils.HTTPClient) utils.HTTPClient {
+	client, ok := httpClient.(*http.Client)
+	if !ok {
+		return httpClient
+	}
+	noRedirectClient := *client
+	noRedirectClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &noRedirectClient
+}
+
+// CheckCertificate : Verifies the certificate an https URL is served with and reports when it expires.
+// The connection's own TLS settings are used when tlsConfig is not nil, and the request goes through its proxy.
+func CheckCertificate(rawURL string, tlsConfig *tls.Config, proxy func(*http.Request) (*url.URL, error), timeout time.Duration) (string, string) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if parsedURL.Scheme != "https" {
+		return CheckSkipped, "connection does not use https"
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{Proxy: proxy, TLSClientConfig: tlsConfig},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(rawURL)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	res.Body.Close()
+	if res.TLS == nil || len(res.TLS.PeerCertificates) == 0 {
+		return CheckFailed, "server sent no certificate"
+	}
+	return CheckOK, "valid until " + res.TLS.PeerCertificates[0].NotAfter.UTC().Format(time.RFC3339)
+}
+
+// AccessTokenStatus : Reports whether an access token is present and unexpired. An expired access token
+// is only a warning when a refresh token is present, as it is refreshed on the next request.
+func AccessTokenStatus(accessToken, refreshToken string, now time.Time) (string, string) {
+	if accessToken == "" {
+		if refreshToken != "" {
+			return CheckWarning, "no access token, a new one will be requested with the refresh token"
+		}
+		return CheckFailed, "no access token, log in with cwctl sectoken get"
+	}
+	expiry, err := TokenExpiry(accessToken)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	expires := expiry.UTC().Format(time.RFC3339)
+	if !expiry.After(now) {
+		if refreshToken != "" {
+			return CheckWarning, "access token expired at " + expires + ", it will be refreshed on the next request"
+		}
+		return CheckFailed, "access token expired at " + expires
+	}
+	return CheckOK, "expires at " + expires
+}
+
+// TokenExpiry : Reads the expiry time from the claims of a JWT access token
+func TokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, err
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, errors.New("access token has no expiry time")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// ConnectionHealthy : Whether none of the checks of a connection failed
+func ConnectionHealthy(checks []ConnectionCheck) bool {
+	for _, check := range checks {
+		if check.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockJWT(claims string) string {
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2lnbmF0dXJl"
+}
+
+func TestAccessTokenStatus(t *testing.T) {
+	now := time.Unix(1580000000, 0)
+	valid := mockJWT(`{"exp": 1580000600}`)
+	expired := mockJWT(`{"exp": 1579999000}`)
+
+	tests := map[string]struct {
+		accessToken  string
+		refreshToken string
+		wantStatus   string
+	}{
+		"unexpired token":                  {accessToken: valid, wantStatus: CheckOK},
+		"expired token with refresh token": {accessToken: expired, refreshToken: "refresh", wantStatus: CheckWarning},
+		"expired token":                    {accessToken: expired, wantStatus: CheckFailed},
+		"no tokens":                        {wantStatus: CheckFailed},
+		"only a refresh token":             {refreshToken: "refresh", wantStatus: CheckWarning},
+		"token is not a JWT":               {accessToken: "opaque", wantStatus: CheckFailed},
+		"token has no expiry":              {accessToken: mockJWT(`{"sub": "developer"}`), wantStatus: CheckFailed},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, _ := AccessTokenStatus(test.accessToken, test.refreshToken, now)
+			assert.Equal(t, test.wantStatus, status)
+		})
+	}
+}
+
+func TestCheckConnectionPFEReady(t *testing.T) {
+	// a gatekeeper that sends requests without the access token to log in, as Keycloak would answer 200
+	gatekeeper := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gatekeeper.Close()
+
+	pfeStatus := func(connection *Connection, accessToken string) string {
+		checks := CheckConnection(&http.Client{}, connection, HealthCheckOptions{PFEURL: gatekeeper.URL, AccessToken: accessToken, Timeout: time.Second})
+		for _, check := range checks {
+			if check.Name == CheckPFEReady {
+				return check.Status
+			}
+		}
+		return ""
+	}
+	remote := &Connection{ID: "REMOTE", URL: gatekeeper.URL}
+	assert.Equal(t, CheckOK, pfeStatus(remote, "token"))
+	assert.Equal(t, CheckFailed, pfeStatus(remote, ""))
+	assert.Equal(t, CheckFailed, pfeStatus(remote, "expired"))
+}
+
+func TestConnectionHealthy(t *testing.T) {
+	assert.True(t, ConnectionHealthy([]ConnectionCheck{{Status: CheckOK}, {Status: CheckSkipped}, {Status: CheckWarning}}))
+	assert.False(t, ConnectionHealthy([]ConnectionCheck{{Status: CheckOK}, {Status: CheckFailed}}))
+}
@@ -0,0 +1,144 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+type (
+	// schemaMigration : Converts a connections config from one schema version to the next
+	schemaMigration struct {
+		from        int
+		description string
+		migrate     func(file []byte) ([]byte, error)
+	}
+
+	// MigrationResult : The schema migrations applied, or that would be applied, to the connections config
+	MigrationResult struct {
+		FromVersion int             `json:"fromVersion"`
+		ToVersion   int             `json:"toVersion"`
+		Steps       []string        `json:"steps"`
+		Backup      string          `json:"backup,omitempty"`
+		Before      json.RawMessage `json:"before,omitempty"`
+		After       json.RawMessage `json:"after,omitempty"`
+	}
+)
+
+// schemaMigrations : Each schema migration, in order. When connectionsSchemaVersion is incremented, add a
+// migration from the previous version here.
+var schemaMigrations = []schemaMigration{
+	{from: 0, description: "rename the name of each connection to id", migrate: migrateV0toV1},
+}
+
+// MigrateConnectionsConfig : Migrates the connections config to the schema version this cwctl uses, keeping a copy
+// of the original file. With dryRun the file is left unchanged and the result holds its contents before and after.
+func MigrateConnectionsConfig(dryRun bool) (*MigrationResult, *ConError) {
+	unlock, conErr := lockConnectionsFile()
+	if conErr != nil {
+		return nil, conErr
+	}
+	defer unlock()
+	return migrateConnectionsConfig(dryRun)
+}
+
+// migrateConnectionsConfig : Migrates the connections config. The caller must hold the connections config lock.
+func migrateConnectionsConfig(dryRun bool) (*MigrationResult, *ConError) {
+	before, err := ioutil.ReadFile(GetConnectionConfigFilename())
+	if err != nil {
+		return nil, &ConError{errOpFileLoad, err, err.Error()}
+	}
+	result, after, conErr := planMigration(schemaMigrations, connectionsSchemaVersion, before)
+	if conErr != nil || len(result.Steps) == 0 {
+		return result, conErr
+	}
+	if dryRun {
+		result.Before, result.After = json.RawMessage(before), json.RawMessage(after)
+		return result, nil
+	}
+
+	result.Backup = GetConnectionConfigFilename() + ".schema" + strconv.Itoa(result.FromVersion) + ".bak"
+	err = utils.WriteFileAtomically(result.Backup, before)
+	if err == nil {
+		err = utils.WriteFileAtomically(GetConnectionConfigFilename(), after)
+	}
+	if err != nil {
+		return nil, &ConError{errOpSchemaUpdate, err, err.Error()}
+	}
+	logr.Infof("Migrated the connections config from schema version %v to %v, the original was saved to %v", result.FromVersion, result.ToVersion, result.Backup)
+	return result, nil
+}
+
+// planMigration : Applies the migrations from the file's schema version to the target version in memory,
+// returning the steps taken and the migrated file
+func planMigration(migrations []schemaMigration, target int, file []byte) (*MigrationResult, []byte, *ConError) {
+	version, conErr := parseSchemaVersion(file)
+	if conErr != nil {
+		return nil, nil, conErr
+	}
+	if version > target {
+		return nil, nil, newerSchemaError(version)
+	}
+
+	result := &MigrationResult{FromVersion: version, ToVersion: target, Steps: []string{}}
+	for version < target {
+		step := findMigration(migrations, version)
+		if step == nil {
+			err := fmt.Errorf("%s %v", textNoMigration, version)
+			return nil, nil, &ConError{errOpSchemaUpdate, err, err.Error()}
+		}
+		migrated, err := step.migrate(file)
+		if err != nil {
+			err = fmt.Errorf("migrating schema version %v: %v", version, err)
+			return nil, nil, &ConError{errOpSchemaUpdate, err, err.Error()}
+		}
+		file = migrated
+		result.Steps = append(result.Steps, fmt.Sprintf("%v to %v: %s", version, version+1, step.description))
+		version++
+	}
+	return result, file, nil
+}
+
+// findMigration : The migration from a schema version, or nil if there is none
+func findMigration(migrations []schemaMigration, from int) *schemaMigration {
+	for i := range migrations {
+		if migrations[i].from == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// parseSchemaVersion : The schema version of a connections config, which is 0 for files written before it was recorded
+func parseSchemaVersion(file []byte) (int, *ConError) {
+	versioned := struct {
+		SchemaVersion int `json:"schemaversion"`
+	}{}
+	err := json.Unmarshal(file, &versioned)
+	if err != nil {
+		return 0, &ConError{errOpFileParse, err, err.Error()}
+	}
+	return versioned.SchemaVersion, nil
+}
+
+// newerSchemaError : The error for a connections config written by a newer cwctl
+func newerSchemaError(version int) *ConError {
+	err := errors.New(textNewerSchema + ": schema version " + strconv.Itoa(version) + " is newer than " + strconv.Itoa(connectionsSchemaVersion))
+	return &ConError{errOpSchemaUpdate, err, err.Error()}
+}
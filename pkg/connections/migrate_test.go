@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const v0ConnectionsFile = `{"connections": [{"name":"local","label": "Codewind local connection","url": ""}]}`
+
+func TestPlanMigration(t *testing.T) {
+	bumpVersion := func(from int) func([]byte) ([]byte, error) {
+		return func(file []byte) ([]byte, error) {
+			return []byte(`{"schemaversion": ` + strconv.Itoa(from+1) + `}`), nil
+		}
+	}
+	migrations := []schemaMigration{
+		{from: 0, description: "first", migrate: bumpVersion(0)},
+		{from: 1, description: "second", migrate: bumpVersion(1)},
+	}
+
+	t.Run("applies each migration in order", func(t *testing.T) {
+		result, file, conErr := planMigration(migrations, 2, []byte(`{}`))
+		assert.Nil(t, conErr)
+		assert.Equal(t, 0, result.FromVersion)
+		assert.Equal(t, 2, result.ToVersion)
+		assert.Equal(t, []string{"0 to 1: first", "1 to 2: second"}, result.Steps)
+		assert.JSONEq(t, `{"schemaversion": 2}`, string(file))
+	})
+	t.Run("starts from the schema version of the file", func(t *testing.T) {
+		result, _, conErr := planMigration(migrations, 2, []byte(`{"schemaversion": 1}`))
+		assert.Nil(t, conErr)
+		assert.Equal(t, []string{"1 to 2: second"}, result.Steps)
+	})
+	t.Run("a current file needs no steps", func(t *testing.T) {
+		result, file, conErr := planMigration(migrations, 2, []byte(`{"schemaversion": 2}`))
+		assert.Nil(t, conErr)
+		assert.Empty(t, result.Steps)
+		assert.JSONEq(t, `{"schemaversion": 2}`, string(file))
+	})
+	t.Run("a newer file is refused", func(t *testing.T) {
+		_, _, conErr := planMigration(migrations, 2, []byte(`{"schemaversion": 3}`))
+		assert.Equal(t, errOpSchemaUpdate, conErr.Op)
+	})
+	t.Run("a missing migration is an error", func(t *testing.T) {
+		_, _, conErr := planMigration(migrations[1:], 2, []byte(`{}`))
+		assert.Equal(t, errOpSchemaUpdate, conErr.Op)
+	})
+	t.Run("a failed migration is an error", func(t *testing.T) {
+		failing := []schemaMigration{{from: 0, migrate: func([]byte) ([]byte, error) { return nil, errors.New("broken") }}}
+		_, _, conErr := planMigration(failing, 1, []byte(`{}`))
+		assert.Equal(t, errOpSchemaUpdate, conErr.Op)
+	})
+}
+
+func TestMigrateConnectionsConfig(t *testing.T) {
+	defer useTempHome(t)()
+	assert.Nil(t, utils.WriteFileAtomically(GetConnectionConfigFilename(), []byte(v0ConnectionsFile)))
+
+	t.Run("a dry run shows the change without making it", func(t *testing.T) {
+		result, conErr := MigrateConnectionsConfig(true)
+		assert.Nil(t, conErr)
+		assert.Equal(t, []string{"0 to 1: rename the name of each connection to id"}, result.Steps)
+		assert.JSONEq(t, v0ConnectionsFile, string(result.Before))
+		assert.Contains(t, string(result.After), `"id": "local"`)
+		assert.Empty(t, result.Backup)
+
+		file, _ := ioutil.ReadFile(GetConnectionConfigFilename())
+		assert.Equal(t, v0ConnectionsFile, string(file))
+	})
+	t.Run("the migration keeps the original file", func(t *testing.T) {
+		result, conErr := MigrateConnectionsConfig(false)
+		assert.Nil(t, conErr)
+		assert.Equal(t, GetConnectionConfigFilename()+".schema0.bak", result.Backup)
+		backup, _ := ioutil.ReadFile(result.Backup)
+		assert.Equal(t, v0ConnectionsFile, string(backup))
+
+		data, conErr := readConnectionsConfig(GetConnectionConfigFilename())
+		assert.Nil(t, conErr)
+		assert.Equal(t, connectionsSchemaVersion, data.SchemaVersion)
+		assert.Equal(t, "local", data.Connections[0].ID)
+	})
+	t.Run("a migrated file needs no steps", func(t *testing.T) {
+		result, conErr := MigrateConnectionsConfig(false)
+		assert.Nil(t, conErr)
+		assert.Empty(t, result.Steps)
+		assert.Empty(t, result.Backup)
+	})
+}
+
+func TestLoadMigratesOlderSchema(t *testing.T) {
+	defer useTempHome(t)()
+	assert.Nil(t, utils.WriteFileAtomically(GetConnectionConfigFilename(), []byte(v0ConnectionsFile)))
+
+	connection, conErr := GetConnectionByID("local")
+	assert.Nil(t, conErr)
+	assert.Equal(t, "local", connection.ID)
+	backup, _ := ioutil.ReadFile(GetConnectionConfigFilename() + ".schema0.bak")
+	assert.Equal(t, v0ConnectionsFile, string(backup))
+}
+
+func TestNewerSchemaIsRefused(t *testing.T) {
+	defer useTempHome(t)()
+	newerFile := `{"schemaversion": 99, "connections": []}`
+	assert.Nil(t, utils.WriteFileAtomically(GetConnectionConfigFilename(), []byte(newerFile)))
+
+	_, conErr := GetConnectionsConfig()
+	assert.Equal(t, errOpSchemaUpdate, conErr.Op)
+	_, conErr = MigrateConnectionsConfig(true)
+	assert.Equal(t, errOpSchemaUpdate, conErr.Op)
+
+	file, _ := ioutil.ReadFile(GetConnectionConfigFilename())
+	assert.Equal(t, newerFile, string(file))
+}
@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// SetProxy : Sets the proxies used for requests to the connection, checking each is a valid proxy URL.
+// A proxy given as host:port is assumed to use the http scheme, or socks5 for the SOCKS5 proxy.
+func (connection *Connection) SetProxy(httpProxy string, httpsProxy string, socksProxy string, noProxy string) *ConError {
+	httpProxy, conErr := normaliseProxyURL(httpProxy, "http", "http", "https")
+	if conErr != nil {
+		return conErr
+	}
+	httpsProxy, conErr = normaliseProxyURL(httpsProxy, "http", "http", "https")
+	if conErr != nil {
+		return conErr
+	}
+	socksProxy, conErr = normaliseProxyURL(socksProxy, "socks5", "socks5")
+	if conErr != nil {
+		return conErr
+	}
+	connection.HTTPProxy = httpProxy
+	connection.HTTPSProxy = httpsProxy
+	connection.SOCKSProxy = socksProxy
+	connection.NoProxy = strings.Replace(strings.TrimSpace(noProxy), " ", "", -1)
+	return nil
+}
+
+// hasProxy : Whether the connection has its own proxy settings
+func (connection *Connection) hasProxy() bool {
+	return connection.HTTPProxy != "" || connection.HTTPSProxy != "" || connection.SOCKSProxy != "" || connection.NoProxy != ""
+}
+
+// ProxyFunc : The proxy to use for each request to the connection. A connection with no proxy settings uses the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. Otherwise only its own settings are used: http requests
+// go through its HTTP proxy, then its SOCKS5 proxy, and https requests through its HTTPS proxy, then its SOCKS5 proxy,
+// then its HTTP proxy. Hosts matching its no-proxy list, and localhost, are always requested directly.
+func (connection *Connection) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if !connection.hasProxy() {
+		return http.ProxyFromEnvironment
+	}
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  connection.HTTPProxy,
+		HTTPSProxy: connection.HTTPSProxy,
+		NoProxy:    connection.NoProxy,
+	}
+	if proxyConfig.HTTPProxy == "" {
+		proxyConfig.HTTPProxy = connection.SOCKSProxy
+	}
+	if proxyConfig.HTTPSProxy == "" {
+		proxyConfig.HTTPSProxy = connection.SOCKSProxy
+	}
+	proxyForURL := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}
+}
+
+// normaliseProxyURL : A proxy URL with its scheme, checking it has a host and one of the allowed schemes
+func normaliseProxyURL(proxy string, defaultScheme string, schemes ...string) (string, *ConError) {
+	proxy = strings.TrimSpace(proxy)
+	if proxy == "" {
+		return "", nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = defaultScheme + "://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err == nil && proxyURL.Host == "" {
+		err = errors.New(textInvalidProxy + " " + proxy)
+	}
+	if err != nil {
+		return "", &ConError{errOpProxy, err, err.Error()}
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(proxyURL.Scheme, scheme) {
+			return strings.TrimSuffix(proxy, "/"), nil
+		}
+	}
+	err = errors.New(textInvalidProxy + " " + proxy + ", the scheme must be " + strings.Join(schemes, " or "))
+	return "", &ConError{errOpProxy, err, err.Error()}
+}
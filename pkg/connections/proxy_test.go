@@ -0,0 +1,99 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProxy(t *testing.T) {
+	t.Run("proxies without a scheme are given one", func(t *testing.T) {
+		connection := Connection{}
+		assert.Nil(t, connection.SetProxy("proxy.test:3128", "https://proxy.test:3129/", "socks.test:1080", " .internal.test, 10.0.0.1 "))
+		assert.Equal(t, "http://proxy.test:3128", connection.HTTPProxy)
+		assert.Equal(t, "https://proxy.test:3129", connection.HTTPSProxy)
+		assert.Equal(t, "socks5://socks.test:1080", connection.SOCKSProxy)
+		assert.Equal(t, ".internal.test,10.0.0.1", connection.NoProxy)
+	})
+	t.Run("an HTTP proxy must use http or https", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetProxy("socks5://socks.test:1080", "", "", "")
+		assert.Equal(t, errOpProxy, conErr.Op)
+	})
+	t.Run("a SOCKS5 proxy must use socks5", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetProxy("", "", "http://proxy.test:3128", "")
+		assert.Equal(t, errOpProxy, conErr.Op)
+	})
+	t.Run("a proxy must have a host", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetProxy("http://", "", "", "")
+		assert.Equal(t, errOpProxy, conErr.Op)
+	})
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxyFor := func(connection Connection, rawURL string) string {
+		req, _ := http.NewRequest("GET", rawURL, nil)
+		proxyURL, err := connection.ProxyFunc()(req)
+		assert.Nil(t, err)
+		if proxyURL == nil {
+			return ""
+		}
+		return proxyURL.String()
+	}
+	all := Connection{HTTPProxy: "http://proxy.test:3128", HTTPSProxy: "http://secure.test:3129", SOCKSProxy: "socks5://socks.test:1080", NoProxy: ".internal.test"}
+	socks := Connection{SOCKSProxy: "socks5://socks.test:1080"}
+
+	tests := map[string]struct {
+		connection Connection
+		url        string
+		want       string
+	}{
+		"http uses the HTTP proxy":                    {all, "http://codewind.test", "http://proxy.test:3128"},
+		"https uses the HTTPS proxy":                  {all, "https://codewind.test", "http://secure.test:3129"},
+		"http falls back to the SOCKS5 proxy":         {socks, "http://codewind.test", "socks5://socks.test:1080"},
+		"https falls back to the SOCKS5 proxy":        {socks, "https://codewind.test", "socks5://socks.test:1080"},
+		"https falls back to the HTTP proxy":          {Connection{HTTPProxy: "http://proxy.test:3128"}, "https://codewind.test", "http://proxy.test:3128"},
+		"the no-proxy list is requested directly":     {all, "https://keycloak.internal.test", ""},
+		"localhost is requested directly":             {all, "http://localhost:9090", ""},
+		"only a no-proxy list bypasses every proxy":   {Connection{NoProxy: "*"}, "https://codewind.test", ""},
+		"the no-proxy list does not match other host": {all, "https://internal.test.example", "http://secure.test:3129"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, proxyFor(test.connection, test.url))
+		})
+	}
+}
+
+func TestConnectionHTTPClientProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	connection := &Connection{HTTPProxy: proxy.URL}
+	httpClient, conErr := ConnectionHTTPClient(&http.Client{}, connection)
+	assert.Nil(t, conErr)
+	req, _ := http.NewRequest("GET", "http://codewind.test/api/v1/environment", nil)
+	res, err := httpClient.Do(req)
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "proxied http://codewind.test/api/v1/environment", string(body))
+}
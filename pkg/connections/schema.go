@@ -13,6 +13,8 @@
 
 package connections
 
+import "encoding/json"
+
 // ConnectionConfigV0 : ConnectionsConfig Schema Version 0
 type ConnectionConfigV0 struct {
 	Connections []ConnectionV0 `json:"connections"`
@@ -43,3 +45,24 @@ type ConnectionV1 struct {
 	Realm    string `json:"realm"`
 	ClientID string `json:"client_id"`
 }
+
+// migrateV0toV1 : Renames the name field of each connection to id
+func migrateV0toV1(file []byte) ([]byte, error) {
+	ConnectionConfig := ConnectionConfigV0{}
+	err := json.Unmarshal(file, &ConnectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newConnectionConfig := ConnectionConfigV1{SchemaVersion: 1}
+	for _, originalConnection := range ConnectionConfig.Connections {
+		connectionJSON, _ := json.Marshal(originalConnection)
+		var upgradedConnection ConnectionV1
+		err = json.Unmarshal(connectionJSON, &upgradedConnection)
+		if err == nil {
+			upgradedConnection.ID = originalConnection.Name
+			newConnectionConfig.Connections = append(newConnectionConfig.Connections, upgradedConnection)
+		}
+	}
+	return json.MarshalIndent(newConnectionConfig, "", "\t")
+}
@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// transportSettings : The settings of a connection a transport is created from
+type transportSettings struct {
+	host                                       string
+	caCert, certFingerprint                    string
+	httpProxy, httpsProxy, socksProxy, noProxy string
+	defaultTLSConfig                           *tls.Config
+}
+
+// pinnedHostTransport : Sends requests to the connection's own host through a transport that checks its pinned
+// fingerprint, and requests to any other host, such as the connection's Keycloak, through one that does not
+type pinnedHostTransport struct {
+	host   string
+	pinned http.RoundTripper
+	other  http.RoundTripper
+}
+
+func (t *pinnedHostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if canonicalHost(req.URL) == t.host {
+		return t.pinned.RoundTrip(req)
+	}
+	return t.other.RoundTrip(req)
+}
+
+// transports : The transport for each set of connection settings, shared so that connections are reused between requests
+var transports sync.Map
+
+// ConnectionHTTPClient : The HTTP client to use for requests to a connection. When the connection has its own
+// trust or proxy settings this is a copy of httpClient using them, otherwise, or when httpClient is not an
+// *http.Client (such as a mock), httpClient is returned unchanged. A pinned fingerprint is only checked for
+// requests to the host in the connection's URL.
+func ConnectionHTTPClient(httpClient utils.HTTPClient, connection *Connection) (utils.HTTPClient, *ConError) {
+	client, ok := httpClient.(*http.Client)
+	if !ok || connection == nil {
+		return httpClient, nil
+	}
+	tlsConfig, conErr := connection.TLSConfig()
+	if conErr != nil || (tlsConfig == nil && !connection.hasProxy()) {
+		return httpClient, conErr
+	}
+	caTLSConfig, conErr := connection.caTLSConfig()
+	if conErr != nil {
+		return httpClient, conErr
+	}
+	settings := transportSettings{
+		caCert:          connection.CACert,
+		certFingerprint: connection.CertFingerprint,
+		httpProxy:       connection.HTTPProxy,
+		httpsProxy:      connection.HTTPSProxy,
+		socksProxy:      connection.SOCKSProxy,
+		noProxy:         connection.NoProxy,
+	}
+	// keep the TLS settings of the global --insecure flag where the connection has none of its own
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		settings.defaultTLSConfig = defaultTransport.TLSClientConfig
+	}
+	if tlsConfig == nil {
+		tlsConfig = settings.defaultTLSConfig
+	}
+	if caTLSConfig == nil {
+		caTLSConfig = settings.defaultTLSConfig
+	}
+
+	var transport http.RoundTripper = newTransport(tlsConfig, connection.ProxyFunc())
+	if connection.CertFingerprint != "" {
+		connectionURL, err := url.Parse(connection.URL)
+		if err != nil {
+			return httpClient, &ConError{errOpTrust, err, err.Error()}
+		}
+		settings.host = canonicalHost(connectionURL)
+		transport = &pinnedHostTransport{settings.host, transport, newTransport(caTLSConfig, connection.ProxyFunc())}
+	}
+	sharedTransport, _ := transports.LoadOrStore(settings, transport)
+	connectionClient := *client
+	connectionClient.Transport = sharedTransport.(http.RoundTripper)
+	return &connectionClient, nil
+}
+
+// canonicalHost : The lower case host and port of a URL, with the default port of its scheme if it has none
+func canonicalHost(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// newTransport : An HTTP transport with the same settings as http.DefaultTransport and the given TLS and proxy settings
+func newTransport(tlsConfig *tls.Config, proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+}
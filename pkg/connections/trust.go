@@ -0,0 +1,122 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// SetTrust : Sets the CA bundle and pinned certificate fingerprint used to verify the connection's certificates,
+// checking the CA bundle can be read and the fingerprint is a SHA-256 fingerprint
+func (connection *Connection) SetTrust(caCert string, fingerprint string) *ConError {
+	caCert = strings.TrimSpace(caCert)
+	if caCert != "" {
+		absPath, err := filepath.Abs(caCert)
+		if err != nil {
+			return &ConError{errOpTrust, err, err.Error()}
+		}
+		caCert = absPath
+		if _, conErr := loadCACertPool(caCert); conErr != nil {
+			return conErr
+		}
+	}
+	fingerprint, conErr := normaliseFingerprint(fingerprint)
+	if conErr != nil {
+		return conErr
+	}
+	connection.CACert = caCert
+	connection.CertFingerprint = fingerprint
+	return nil
+}
+
+// TLSConfig : The TLS settings for requests to the connection's own host, or nil if it uses the default trust.
+// A pinned fingerprint must match the server's certificate, and is the only check made unless a CA bundle is also set.
+func (connection *Connection) TLSConfig() (*tls.Config, *ConError) {
+	tlsConfig, conErr := connection.caTLSConfig()
+	if conErr != nil || connection.CertFingerprint == "" {
+		return tlsConfig, conErr
+	}
+	fingerprint, conErr := normaliseFingerprint(connection.CertFingerprint)
+	if conErr != nil {
+		return nil, conErr
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New(textNoPeerCertificate)
+		}
+		if actual := certificateFingerprint(rawCerts[0]); actual != fingerprint {
+			return errors.New(textFingerprintMismatch + " " + actual)
+		}
+		return nil
+	}
+	return tlsConfig, nil
+}
+
+// caTLSConfig : The TLS settings for requests to other hosts of the connection, such as its Keycloak, which
+// use the CA bundle but not the pinned fingerprint, or nil if it has no CA bundle
+func (connection *Connection) caTLSConfig() (*tls.Config, *ConError) {
+	if connection.CACert == "" {
+		return nil, nil
+	}
+	pool, conErr := loadCACertPool(connection.CACert)
+	if conErr != nil {
+		return nil, conErr
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// loadCACertPool : The system certificate pool with the certificates in a PEM encoded CA bundle added
+func loadCACertPool(caCert string) (*x509.CertPool, *ConError) {
+	pem, err := ioutil.ReadFile(caCert)
+	if err != nil {
+		return nil, &ConError{errOpTrust, err, err.Error()}
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		err := errors.New(textInvalidCACert + " " + caCert)
+		return nil, &ConError{errOpTrust, err, err.Error()}
+	}
+	return pool, nil
+}
+
+// normaliseFingerprint : A SHA-256 fingerprint as lower case hex, accepting the colon separated form shown by browsers and openssl
+func normaliseFingerprint(fingerprint string) (string, *ConError) {
+	fingerprint = strings.ToLower(strings.Replace(strings.TrimSpace(fingerprint), ":", "", -1))
+	if fingerprint == "" {
+		return "", nil
+	}
+	decoded, err := hex.DecodeString(fingerprint)
+	if err != nil || len(decoded) != sha256.Size {
+		err := errors.New(textInvalidFingerprint)
+		return "", &ConError{errOpTrust, err, err.Error()}
+	}
+	return fingerprint, nil
+}
+
+// certificateFingerprint : The SHA-256 fingerprint of a DER encoded certificate as lower case hex
+func certificateFingerprint(cert []byte) string {
+	sum := sha256.Sum256(cert)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTrust(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trust")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	assert.Nil(t, ioutil.WriteFile(notPEM, []byte("not a certificate"), 0644))
+	fingerprint := strings.Repeat("AB:", 31) + "AB"
+
+	t.Run("a fingerprint is stored as lower case hex", func(t *testing.T) {
+		connection := Connection{}
+		assert.Nil(t, connection.SetTrust("", fingerprint))
+		assert.Equal(t, strings.Repeat("ab", 32), connection.CertFingerprint)
+	})
+	t.Run("a fingerprint must be SHA-256", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetTrust("", "AB:CD")
+		assert.Equal(t, errOpTrust, conErr.Op)
+	})
+	t.Run("a CA bundle must exist", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetTrust(filepath.Join(dir, "missing.pem"), "")
+		assert.Equal(t, errOpTrust, conErr.Op)
+	})
+	t.Run("a CA bundle must contain certificates", func(t *testing.T) {
+		connection := Connection{}
+		conErr := connection.SetTrust(notPEM, "")
+		assert.Equal(t, errOpTrust, conErr.Op)
+	})
+	t.Run("no trust settings uses the default", func(t *testing.T) {
+		connection := Connection{}
+		assert.Nil(t, connection.SetTrust(" ", ""))
+		tlsConfig, conErr := connection.TLSConfig()
+		assert.Nil(t, conErr)
+		assert.Nil(t, tlsConfig)
+	})
+}
+
+func TestConnectionHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "trust")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	caCert := filepath.Join(dir, "ca.pem")
+	serverCert := server.Certificate()
+	assert.Nil(t, ioutil.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Raw}), 0644))
+	serverFingerprint := certificateFingerprint(serverCert.Raw)
+
+	getURL := func(connection *Connection, requestURL string) error {
+		httpClient, conErr := ConnectionHTTPClient(&http.Client{}, connection)
+		if conErr != nil {
+			return conErr
+		}
+		req, _ := http.NewRequest("GET", requestURL, nil)
+		res, err := httpClient.Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+	get := func(connection *Connection) error {
+		connection.URL = server.URL
+		return getURL(connection, server.URL)
+	}
+
+	t.Run("the server is not trusted by default", func(t *testing.T) {
+		assert.NotNil(t, get(&Connection{}))
+	})
+	t.Run("the server is trusted with its CA bundle", func(t *testing.T) {
+		assert.Nil(t, get(&Connection{CACert: caCert}))
+	})
+	t.Run("the server is trusted with its pinned fingerprint", func(t *testing.T) {
+		assert.Nil(t, get(&Connection{CertFingerprint: serverFingerprint}))
+	})
+	t.Run("the server is trusted with both", func(t *testing.T) {
+		assert.Nil(t, get(&Connection{CACert: caCert, CertFingerprint: serverFingerprint}))
+	})
+	t.Run("a different pinned fingerprint is refused", func(t *testing.T) {
+		err := get(&Connection{CertFingerprint: strings.Repeat("0", 64)})
+		assert.Contains(t, err.Error(), textFingerprintMismatch)
+	})
+	t.Run("only the connection's own host is checked against the pinned fingerprint", func(t *testing.T) {
+		keycloak := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer keycloak.Close()
+		pinnedElsewhere := &Connection{URL: server.URL, CACert: caCert, CertFingerprint: strings.Repeat("0", 64)}
+		assert.Contains(t, getURL(pinnedElsewhere, server.URL).Error(), textFingerprintMismatch)
+		assert.Nil(t, getURL(pinnedElsewhere, keycloak.URL))
+
+		pinnedOnly := &Connection{URL: server.URL, CertFingerprint: serverFingerprint}
+		assert.Nil(t, getURL(pinnedOnly, server.URL))
+		err := getURL(pinnedOnly, keycloak.URL)
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), textFingerprintMismatch)
+	})
+	t.Run("a mock client is returned unchanged", func(t *testing.T) {
+		mock := &clientMockGatekeeper{}
+		httpClient, conErr := ConnectionHTTPClient(mock, &Connection{CACert: caCert})
+		assert.Nil(t, conErr)
+		assert.Equal(t, mock, httpClient)
+	})
+}
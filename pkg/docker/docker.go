@@ -722,9 +722,14 @@ func InspectContainer(dockerClient DockerClient, containerID string) (types.Cont
 
 //GetContainerLogs : returns the container log for the specified container.
 func GetContainerLogs(dockerClient DockerClient, containerID string) (io.ReadCloser, *DockerError) {
+	return GetContainerLogsWithOptions(dockerClient, containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+}
+
+//GetContainerLogsWithOptions : returns the container log for the specified container, selected by the given options.
+func GetContainerLogsWithOptions(dockerClient DockerClient, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, *DockerError) {
 	ctx := context.Background()
 
-	containerLogStream, err := dockerClient.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	containerLogStream, err := dockerClient.ContainerLogs(ctx, containerID, options)
 	if err != nil {
 		return nil, &DockerError{ErrOpContainerLogs, err, err.Error()}
 	}
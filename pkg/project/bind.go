@@ -15,6 +15,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -66,7 +67,7 @@ func BindProject(c *cli.Context) (*BindResponse, *ProjectError) {
 	name := strings.TrimSpace(c.String("name"))
 	language := strings.TrimSpace(c.String("language"))
 	buildType := strings.TrimSpace(c.String("type"))
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connections.ResolveConnectionID(c.String("conid"))
 	return Bind(projectPath, name, language, buildType, conID)
 }
 
@@ -105,18 +106,50 @@ func Bind(projectPath string, name string, language string, projectType string,
 	}
 	projectID := projectInfo.ProjectID
 
+	// The project is now registered with Codewind, so any failure from here on must unbind it again
 	// Sync all the project files
 	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, 0, conInfo)
+	if syncErr != nil {
+		return nil, rollbackBind(client, conInfo, conURL, projectID, syncErr)
+	}
 
 	// Call bind/end to complete
-	completeStatus, completeStatusCode := completeBind(client, projectID, conURL, conInfo)
+	completeStatus, completeStatusCode, completeErr := completeBind(client, projectID, conURL, conInfo)
+	if completeErr != nil {
+		return nil, rollbackBind(client, conInfo, conURL, projectID, completeErr)
+	}
+	// We can ignore errors as the index is rebuilt when a project is missing from it
+	AddProjectToIndex(projectID, conID, name)
+
 	response := BindResponse{
 		ProjectID:     projectID,
 		UploadedFiles: syncInfo.UploadedFileList,
 		Status:        completeStatus,
 		StatusCode:    completeStatusCode,
 	}
-	return &response, syncErr
+	return &response, nil
+}
+
+// rollbackBind unbinds a partially bound project and removes its connection file, returning
+// an error that describes both the failure that caused the rollback and the outcome of the rollback
+func rollbackBind(client utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, bindErr *ProjectError) *ProjectError {
+	cleanupOutcomes := []string{}
+
+	unbindErr := Unbind(client, conInfo, conURL, projectID)
+	if unbindErr != nil {
+		cleanupOutcomes = append(cleanupOutcomes, "unable to unbind project "+projectID+" ("+unbindErr.Desc+"), remove it with 'cwctl project remove --id "+projectID+"'")
+	} else {
+		cleanupOutcomes = append(cleanupOutcomes, "project "+projectID+" was unbound")
+	}
+
+	// The connection file may never have been written, so only report failures to delete an existing one
+	removeErr := RemoveConnectionFile(projectID)
+	if removeErr != nil && !os.IsNotExist(removeErr.Err) {
+		cleanupOutcomes = append(cleanupOutcomes, "unable to remove project connection file ("+removeErr.Desc+")")
+	}
+
+	err := errors.New("bind failed: " + strings.TrimSpace(bindErr.Desc) + "; rollback: " + strings.Join(cleanupOutcomes, ", "))
+	return &ProjectError{bindErr.Op, err, err.Error()}
 }
 
 func bindToPFE(client utils.HTTPClient, bindRequest BindRequest, conInfo *connections.Connection, conURL string) (*BindResponse, *ProjectError) {
@@ -163,7 +196,7 @@ func bindToPFE(client utils.HTTPClient, bindRequest BindRequest, conInfo *connec
 	return projectInfo, nil
 }
 
-func completeBind(client utils.HTTPClient, projectID string, conURL string, connection *connections.Connection) (string, int) {
+func completeBind(client utils.HTTPClient, projectID string, conURL string, connection *connections.Connection) (string, int, *ProjectError) {
 	bindEndURL := conURL + "/api/v1/projects/" + projectID + "/bind/end"
 
 	payload := &BindEndRequest{ProjectID: projectID}
@@ -171,11 +204,19 @@ func completeBind(client utils.HTTPClient, projectID string, conURL string, conn
 
 	// Make the request to end the sync process.
 	request, err := http.NewRequest("POST", bindEndURL, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return "", 0, &ProjectError{errOpRequest, err, err.Error()}
+	}
 	request.Header.Set("Content-Type", "application/json")
 	resp, httpSecError := sechttp.DispatchHTTPRequest(client, request, connection)
-
 	if httpSecError != nil {
-		logr.Errorln(err)
+		return "", 0, &ProjectError{errOpResponse, httpSecError.Err, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("Project bind end failed with status code %d", resp.StatusCode)
+		return resp.Status, resp.StatusCode, &ProjectError{errOpBind, err, err.Error()}
 	}
-	return resp.Status, resp.StatusCode
+	return resp.Status, resp.StatusCode, nil
 }
@@ -116,8 +116,48 @@ func TestBindToPFE(t *testing.T) {
 }
 
 func TestCompleteBind(t *testing.T) {
-	mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: nil}
 	mockConnection := connections.Connection{ID: "local"}
-	_, gotStatusCode := completeBind(mockClient, "testID", "dummyURL", &mockConnection)
-	assert.Equal(t, http.StatusOK, gotStatusCode)
+
+	t.Run("Expect success - bind end accepted", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: body}
+		_, gotStatusCode, projErr := completeBind(mockClient, "testID", "dummyURL", &mockConnection)
+		assert.Nil(t, projErr)
+		assert.Equal(t, http.StatusOK, gotStatusCode)
+	})
+
+	t.Run("Expect failure - pfe returns an error status", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusInternalServerError, Body: body}
+		_, gotStatusCode, projErr := completeBind(mockClient, "testID", "dummyURL", &mockConnection)
+		assert.Equal(t, http.StatusInternalServerError, gotStatusCode)
+		assert.Equal(t, errOpBind, projErr.Op)
+	})
+
+	t.Run("Expect failure - request fails", func(t *testing.T) {
+		_, _, projErr := completeBind(&security.ClientMockRequestFail{}, "testID", "dummyURL", &mockConnection)
+		assert.Equal(t, errOpResponse, projErr.Op)
+	})
+}
+
+func TestRollbackBind(t *testing.T) {
+	mockConnection := connections.Connection{ID: "local"}
+	syncErr := &ProjectError{errOpSync, errors.New("error walking the path"), "error walking the path"}
+
+	t.Run("Expect unbind - error reports the failure and the rollback", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusAccepted, Body: body}
+		projErr := rollbackBind(mockClient, &mockConnection, "dummyurl", "mockID", syncErr)
+		assert.Equal(t, errOpSync, projErr.Op)
+		assert.Equal(t, "bind failed: error walking the path; rollback: project mockID was unbound", projErr.Desc)
+	})
+
+	t.Run("Expect failed unbind - error reports both failures", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusInternalServerError, Body: body}
+		projErr := rollbackBind(mockClient, &mockConnection, "dummyurl", "mockID", syncErr)
+		assert.Equal(t, errOpSync, projErr.Op)
+		assert.Contains(t, projErr.Desc, "bind failed: error walking the path")
+		assert.Contains(t, projErr.Desc, "unable to unbind project mockID (Project unbind failed with status code 500)")
+	})
 }
@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"sync"
+)
+
+type (
+	// ProjectSelector picks projects on a connection for a bulk operation
+	ProjectSelector struct {
+		All         bool
+		NamePattern string // shell pattern matched against the project name, e.g. node-*
+		Language    string
+		Status      string // app status, e.g. started or stopped
+	}
+
+	// BulkResult is the outcome of a bulk operation for one project
+	BulkResult struct {
+		ProjectID string `json:"projectID"`
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		Error     string `json:"error,omitempty"`
+	}
+)
+
+const (
+	bulkStatusOK     = "OK"
+	bulkStatusFailed = "Failed"
+)
+
+// IsSet reports whether any selector has been given
+func (selector ProjectSelector) IsSet() bool {
+	return selector.All || selector.NamePattern != "" || selector.Language != "" || selector.Status != ""
+}
+
+// ValidateSelection checks that a command has been given either a single project or a selector, but not both
+func ValidateSelection(hasProject bool, selector ProjectSelector) *ProjectError {
+	if !hasProject && !selector.IsSet() {
+		err := errors.New(textNoProjectSelector)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if hasProject && selector.IsSet() {
+		err := errors.New(textSelectorWithID)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	return nil
+}
+
+// SelectProjects returns the projects that match every given selector. With All set, every project matches
+// unless it is excluded by another selector.
+func SelectProjects(projects []Project, selector ProjectSelector) ([]Project, *ProjectError) {
+	if !selector.IsSet() {
+		err := errors.New(textNoProjectSelector)
+		return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if selector.NamePattern != "" {
+		if _, err := path.Match(selector.NamePattern, ""); err != nil {
+			err = errors.New(textInvalidNamePattern + ": " + selector.NamePattern)
+			return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+		}
+	}
+
+	selected := []Project{}
+	for _, project := range projects {
+		if selector.NamePattern != "" {
+			if matched, _ := path.Match(selector.NamePattern, project.Name); !matched {
+				continue
+			}
+		}
+		if selector.Language != "" && !strings.EqualFold(selector.Language, project.Language) {
+			continue
+		}
+		if selector.Status != "" && !strings.EqualFold(selector.Status, project.AppStatus) {
+			continue
+		}
+		selected = append(selected, project)
+	}
+	return selected, nil
+}
+
+// RunBulk calls the operation for each project, working on at most concurrency projects at once.
+// The results are in the same order as the projects.
+func RunBulk(projects []Project, concurrency int, operation func(Project) error) []BulkResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]BulkResult, len(projects))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, project Project) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := BulkResult{ProjectID: project.ProjectID, Name: project.Name, Status: bulkStatusOK}
+			if err := operation(project); err != nil {
+				result.Status = bulkStatusFailed
+				result.Error = err.Error()
+				if projErr, ok := err.(*ProjectError); ok {
+					result.Error = projErr.Desc
+				}
+			}
+			results[i] = result
+		}(i, project)
+	}
+	wg.Wait()
+	return results
+}
+
+// BulkFailed reports whether the operation failed for any project
+func BulkFailed(results []BulkResult) bool {
+	for _, result := range results {
+		if result.Status != bulkStatusOK {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectProjects(t *testing.T) {
+	projects := []Project{
+		{ProjectID: "1", Name: "node-api", Language: "nodejs", AppStatus: "started"},
+		{ProjectID: "2", Name: "node-web", Language: "nodejs", AppStatus: "stopped"},
+		{ProjectID: "3", Name: "java-api", Language: "java", AppStatus: "started"},
+	}
+
+	tests := map[string]struct {
+		selector ProjectSelector
+		wantIDs  []string
+		wantOp   string
+	}{
+		"success case: all projects": {
+			selector: ProjectSelector{All: true},
+			wantIDs:  []string{"1", "2", "3"},
+		},
+		"success case: name pattern": {
+			selector: ProjectSelector{NamePattern: "node-*"},
+			wantIDs:  []string{"1", "2"},
+		},
+		"success case: language and status": {
+			selector: ProjectSelector{Language: "NodeJS", Status: "started"},
+			wantIDs:  []string{"1"},
+		},
+		"success case: nothing matches": {
+			selector: ProjectSelector{Language: "python"},
+			wantIDs:  []string{},
+		},
+		"fail case: no selector": {
+			selector: ProjectSelector{},
+			wantOp:   ErrOpInvalidOptions,
+		},
+		"fail case: invalid name pattern": {
+			selector: ProjectSelector{NamePattern: "node-["},
+			wantOp:   ErrOpInvalidOptions,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selected, projErr := SelectProjects(projects, test.selector)
+			if test.wantOp != "" {
+				assert.Equal(t, test.wantOp, projErr.Op)
+				return
+			}
+			assert.Nil(t, projErr)
+			ids := []string{}
+			for _, project := range selected {
+				ids = append(ids, project.ProjectID)
+			}
+			assert.Equal(t, test.wantIDs, ids)
+		})
+	}
+}
+
+func TestValidateSelection(t *testing.T) {
+	assert.Nil(t, ValidateSelection(true, ProjectSelector{}))
+	assert.Nil(t, ValidateSelection(false, ProjectSelector{Language: "java"}))
+	assert.Equal(t, ErrOpInvalidOptions, ValidateSelection(false, ProjectSelector{}).Op)
+	assert.Equal(t, ErrOpInvalidOptions, ValidateSelection(true, ProjectSelector{All: true}).Op)
+}
+
+func TestRunBulk(t *testing.T) {
+	projects := []Project{
+		{ProjectID: "1", Name: "one"},
+		{ProjectID: "2", Name: "two"},
+		{ProjectID: "3", Name: "three"},
+		{ProjectID: "4", Name: "four"},
+		{ProjectID: "5", Name: "five"},
+	}
+
+	t.Run("success case: results are in order and failures are reported", func(t *testing.T) {
+		results := RunBulk(projects, 2, func(project Project) error {
+			if project.ProjectID == "2" {
+				return errors.New("restart failed")
+			}
+			if project.ProjectID == "4" {
+				err := errors.New("not found")
+				return &ProjectError{errOpNotFound, err, "project not found"}
+			}
+			return nil
+		})
+		assert.Equal(t, []BulkResult{
+			{ProjectID: "1", Name: "one", Status: "OK"},
+			{ProjectID: "2", Name: "two", Status: "Failed", Error: "restart failed"},
+			{ProjectID: "3", Name: "three", Status: "OK"},
+			{ProjectID: "4", Name: "four", Status: "Failed", Error: "project not found"},
+			{ProjectID: "5", Name: "five", Status: "OK"},
+		}, results)
+		assert.True(t, BulkFailed(results))
+	})
+
+	t.Run("success case: concurrency is bounded", func(t *testing.T) {
+		var mutex sync.Mutex
+		running, maxRunning := 0, 0
+		results := RunBulk(projects, 2, func(project Project) error {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return nil
+		})
+		assert.Equal(t, 2, maxRunning)
+		assert.False(t, BulkFailed(results))
+	})
+}
@@ -0,0 +1,371 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// BundleManifest describes the project held in an export bundle
+	BundleManifest struct {
+		Version      int    `json:"version"`
+		ProjectID    string `json:"projectID"`
+		Name         string `json:"name"`
+		Language     string `json:"language"`
+		ProjectType  string `json:"projectType"`
+		ConnectionID string `json:"connectionID"`
+		ExportTime   int64  `json:"exportTime"`
+		Links        []Link `json:"links"`
+	}
+
+	// ExportResponse represents the result of exporting a project to a bundle
+	ExportResponse struct {
+		BundlePath string         `json:"bundlePath"`
+		Manifest   BundleManifest `json:"manifest"`
+	}
+
+	// SkippedLink is a link from a bundle that could not be restored
+	SkippedLink struct {
+		Link   Link   `json:"link"`
+		Reason string `json:"reason"`
+	}
+
+	// ImportResponse represents the result of importing a project from a bundle
+	ImportResponse struct {
+		Path          string         `json:"projectPath"`
+		Bind          *BindResponse  `json:"bind"`
+		RestoredLinks []Link         `json:"restoredLinks"`
+		SkippedLinks  []SkippedLink  `json:"skippedLinks"`
+		Manifest      BundleManifest `json:"manifest"`
+	}
+)
+
+const bundleVersion = 1
+const bundleManifestName = "manifest.json"
+const bundleProjectDir = "project"
+
+// ExportProject writes a bound project, its settings, links and bind metadata to a single bundle file
+func ExportProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID, bundlePath string) (*ExportResponse, *ProjectError) {
+	project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	links, projErr := GetProjectLinks(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	projErr = checkProjectPathExists(project.LocationOnDisk)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	if bundlePath == "" {
+		bundlePath = project.Name + ".cwbundle.tar.gz"
+	}
+
+	manifest := BundleManifest{
+		Version:      bundleVersion,
+		ProjectID:    project.ProjectID,
+		Name:         project.Name,
+		Language:     project.Language,
+		ProjectType:  project.ProjectType,
+		ConnectionID: conInfo.ID,
+		ExportTime:   utils.CreateTimestamp(),
+		Links:        links,
+	}
+
+	err := writeBundle(bundlePath, project.LocationOnDisk, manifest)
+	if err != nil {
+		os.Remove(bundlePath)
+		return nil, &ProjectError{errOpExport, err, err.Error()}
+	}
+	return &ExportResponse{BundlePath: bundlePath, Manifest: manifest}, nil
+}
+
+// ImportProject extracts a bundle to the given path, binds it to the given connection
+// and recreates any links whose target project exists on that connection
+func ImportProject(httpClient utils.HTTPClient, bundlePath, projectPath, conID string) (*ImportResponse, *ProjectError) {
+	manifest, err := readBundleManifest(bundlePath)
+	if err != nil {
+		return nil, &ProjectError{errOpImport, err, err.Error()}
+	}
+
+	if projectPath == "" {
+		projectPath = manifest.Name
+	}
+	projectPath, err = filepath.Abs(projectPath)
+	if err != nil {
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	projErr := checkProjectDirIsEmpty(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	conInfo, conURL, projErr := getConnectionAndURL(conID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	existed := utils.PathExists(projectPath)
+	err = installBundle(bundlePath, projectPath)
+	if err != nil {
+		return nil, &ProjectError{errOpImport, err, err.Error()}
+	}
+
+	bindResponse, projErr := Bind(projectPath, manifest.Name, manifest.Language, manifest.ProjectType, conID)
+	if projErr != nil {
+		// leave the project directory as it was, so the import can be run again
+		os.RemoveAll(projectPath)
+		if existed {
+			os.Mkdir(projectPath, 0755)
+		}
+		return nil, projErr
+	}
+
+	response := ImportResponse{
+		Path:          projectPath,
+		Bind:          bindResponse,
+		RestoredLinks: []Link{},
+		SkippedLinks:  []SkippedLink{},
+		Manifest:      *manifest,
+	}
+	response.RestoredLinks, response.SkippedLinks = restoreLinks(httpClient, conInfo, conURL, bindResponse.ProjectID, manifest.Links)
+	return &response, nil
+}
+
+// restoreLinks recreates links on a project, finding each target project by name
+func restoreLinks(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string, links []Link) ([]Link, []SkippedLink) {
+	restored := []Link{}
+	skipped := []SkippedLink{}
+	for _, link := range links {
+		targetID, projErr := GetProjectIDFromName(httpClient, conInfo, conURL, link.ProjectName)
+		if projErr != nil {
+			skipped = append(skipped, SkippedLink{link, textProjectLinkTargetNotFound})
+			continue
+		}
+		projErr = CreateProjectLink(httpClient, conInfo, conURL, projectID, targetID, link.EnvName)
+		if projErr != nil {
+			skipped = append(skipped, SkippedLink{link, projErr.Desc})
+			continue
+		}
+		link.ProjectID = targetID
+		restored = append(restored, link)
+	}
+	return restored, skipped
+}
+
+// writeBundle writes the manifest and the project files that would be synced to PFE to a tar.gz file
+func writeBundle(bundlePath, projectPath string, manifest BundleManifest) error {
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer bundleFile.Close()
+	gzipWriter := gzip.NewWriter(bundleFile)
+	defer gzipWriter.Close()
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tarWriter.WriteHeader(&tar.Header{Name: bundleManifestName, Mode: 0644, Size: int64(len(manifestJSON)), Typeflag: tar.TypeReg})
+	if err != nil {
+		return err
+	}
+	if _, err = tarWriter.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	ignoredPaths := retrieveIgnoredPathsList(projectPath)
+	absBundlePath, _ := filepath.Abs(bundlePath)
+	err = filepath.Walk(projectPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == projectPath {
+			return nil
+		}
+		relativePath := filepath.ToSlash(filePath[(len(projectPath) + 1):])
+
+		if info.IsDir() {
+			if ignoreFileOrDirectory(relativePath, true, ignoredPaths) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// the Codewind settings files are always exported, even if they are ignored for syncing
+		isSettingsFile := relativePath == cwSettingsFilename || relativePath == ".cw-refpaths.json"
+		if !info.Mode().IsRegular() || (!isSettingsFile && ignoreFileOrDirectory(relativePath, false, ignoredPaths)) {
+			return nil
+		}
+		if absFilePath, _ := filepath.Abs(filePath); absFilePath == absBundlePath {
+			return nil
+		}
+		return addFileToBundle(tarWriter, filePath, path.Join(bundleProjectDir, relativePath), info)
+	})
+	if err != nil {
+		return err
+	}
+
+	// close the writers here, rather than only deferring, so a truncated bundle is reported
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func addFileToBundle(tarWriter *tar.Writer, filePath, name string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err = tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// readBundleManifest returns the manifest stored in a bundle
+func readBundleManifest(bundlePath string) (*BundleManifest, error) {
+	var manifest *BundleManifest
+	err := walkBundle(bundlePath, func(header *tar.Header, reader io.Reader) error {
+		if header.Name != bundleManifestName {
+			return nil
+		}
+		contents, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		manifest = &BundleManifest{}
+		return json.Unmarshal(contents, manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, errors.New(textBundleNoManifest)
+	}
+	if manifest.Version > bundleVersion {
+		return nil, fmt.Errorf("%s: %d", textBundleVersion, manifest.Version)
+	}
+	return manifest, nil
+}
+
+// extractBundle writes the project files in a bundle to the given directory
+func extractBundle(bundlePath, projectPath string) error {
+	prefix := bundleProjectDir + "/"
+	return walkBundle(bundlePath, func(header *tar.Header, reader io.Reader) error {
+		if header.Typeflag != tar.TypeReg || !strings.HasPrefix(header.Name, prefix) {
+			return nil
+		}
+		relativePath := path.Clean(strings.TrimPrefix(header.Name, prefix))
+		if relativePath == ".." || strings.HasPrefix(relativePath, "../") || path.IsAbs(relativePath) {
+			return fmt.Errorf("%s: %s", textBundleInvalidPath, header.Name)
+		}
+		target := filepath.Join(projectPath, filepath.FromSlash(relativePath))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(file, reader)
+		return err
+	})
+}
+
+// installBundle extracts the project files of a bundle to a temporary directory next to the project directory,
+// then renames it into place, so an extraction that fails part way leaves nothing behind. The project
+// directory must be empty or not exist.
+func installBundle(bundlePath, projectPath string) error {
+	parentDir := filepath.Dir(projectPath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return err
+	}
+	tempDir, err := ioutil.TempDir(parentDir, "."+filepath.Base(projectPath)+".import")
+	if err != nil {
+		return err
+	}
+	err = extractBundle(bundlePath, tempDir)
+	if err == nil {
+		err = os.Chmod(tempDir, 0755)
+	}
+	replaced := false
+	if err == nil && utils.PathExists(projectPath) {
+		// the empty project directory is replaced, and put back if the rename fails
+		err = os.Remove(projectPath)
+		replaced = err == nil
+	}
+	if err == nil {
+		err = os.Rename(tempDir, projectPath)
+	}
+	if err != nil {
+		os.RemoveAll(tempDir)
+		if replaced {
+			os.Mkdir(projectPath, 0755)
+		}
+	}
+	return err
+}
+
+// walkBundle calls the given function for each entry in a bundle
+func walkBundle(bundlePath string, walkFunc func(header *tar.Header, reader io.Reader) error) error {
+	bundleFile, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer bundleFile.Close()
+	gzipReader, err := gzip.NewReader(bundleFile)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = walkFunc(header, tarReader); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	projectPath := filepath.Join(testDir, "exportProject")
+	files := map[string]string{
+		"package.json":              "{}",
+		"src/index.js":              "console.log('hello')",
+		".cw-settings":              `{"ignoredPaths": ["*.log", ".cw-settings"]}`,
+		".cw-refpaths.json":         `{"refPaths": []}`,
+		"debug.log":                 "ignored by .cw-settings",
+		"node_modules/dep/index.js": "ignored by default",
+	}
+	for name, contents := range files {
+		filePath := filepath.Join(projectPath, name)
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		ioutil.WriteFile(filePath, []byte(contents), 0644)
+	}
+
+	manifest := BundleManifest{
+		Version:     bundleVersion,
+		ProjectID:   "1234",
+		Name:        "exportProject",
+		Language:    "javascript",
+		ProjectType: "nodejs",
+		Links:       []Link{{ProjectID: "5678", ProjectName: "backend", EnvName: "BACKEND_URL"}},
+	}
+	bundlePath := filepath.Join(testDir, "export.cwbundle.tar.gz")
+	err := writeBundle(bundlePath, projectPath, manifest)
+	require.Nil(t, err)
+
+	t.Run("success case: manifest is read back", func(t *testing.T) {
+		got, err := readBundleManifest(bundlePath)
+		require.Nil(t, err)
+		assert.Equal(t, manifest, *got)
+	})
+
+	t.Run("success case: synced and settings files are extracted, ignored files are not", func(t *testing.T) {
+		importPath := filepath.Join(testDir, "importProject")
+		err := extractBundle(bundlePath, importPath)
+		require.Nil(t, err)
+		for _, name := range []string{"package.json", "src/index.js", ".cw-settings", ".cw-refpaths.json"} {
+			contents, err := ioutil.ReadFile(filepath.Join(importPath, name))
+			assert.Nil(t, err)
+			assert.Equal(t, files[name], string(contents))
+		}
+		assert.False(t, fileExists(filepath.Join(importPath, "debug.log")))
+		assert.False(t, fileExists(filepath.Join(importPath, "node_modules")))
+	})
+}
+
+func TestExtractBundleRejectsPathsOutsideProject(t *testing.T) {
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	os.MkdirAll(testDir, 0755)
+
+	bundlePath := filepath.Join(testDir, "bad.cwbundle.tar.gz")
+	bundleFile, _ := os.Create(bundlePath)
+	gzipWriter := gzip.NewWriter(bundleFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	contents := []byte("escaped")
+	tarWriter.WriteHeader(&tar.Header{Name: "project/../../escaped", Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+	tarWriter.Write(contents)
+	tarWriter.Close()
+	gzipWriter.Close()
+	bundleFile.Close()
+
+	err := extractBundle(bundlePath, filepath.Join(testDir, "importProject"))
+	assert.Error(t, err)
+	assert.False(t, fileExists(filepath.Join(testDir, "escaped")))
+}
+
+func TestInstallBundle(t *testing.T) {
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	os.MkdirAll(testDir, 0755)
+
+	t.Run("success case: files are extracted into an existing empty directory", func(t *testing.T) {
+		bundlePath := filepath.Join(testDir, "good.cwbundle.tar.gz")
+		writeTestBundle(bundlePath, "project/package.json")
+		importPath := filepath.Join(testDir, "emptyProject")
+		os.MkdirAll(importPath, 0755)
+
+		err := installBundle(bundlePath, importPath)
+		assert.Nil(t, err)
+		assert.True(t, fileExists(filepath.Join(importPath, "package.json")))
+	})
+
+	t.Run("fail case: a failed extraction leaves nothing behind", func(t *testing.T) {
+		bundlePath := filepath.Join(testDir, "bad.cwbundle.tar.gz")
+		writeTestBundle(bundlePath, "project/package.json", "project/../../escaped")
+		importPath := filepath.Join(testDir, "newProject")
+
+		err := installBundle(bundlePath, importPath)
+		assert.Error(t, err)
+		assert.False(t, fileExists(importPath))
+		files, _ := ioutil.ReadDir(testDir)
+		for _, file := range files {
+			assert.False(t, strings.Contains(file.Name(), ".import"), file.Name())
+		}
+	})
+}
+
+// writeTestBundle writes a bundle holding a file for each of the given paths
+func writeTestBundle(bundlePath string, names ...string) {
+	bundleFile, _ := os.Create(bundlePath)
+	gzipWriter := gzip.NewWriter(bundleFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+	contents := []byte("contents")
+	for _, name := range names {
+		tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+		tarWriter.Write(contents)
+	}
+	tarWriter.Close()
+	gzipWriter.Close()
+	bundleFile.Close()
+}
+
+func TestReadBundleManifestWithoutManifest(t *testing.T) {
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	os.MkdirAll(testDir, 0755)
+
+	bundlePath := filepath.Join(testDir, "empty.cwbundle.tar.gz")
+	bundleFile, _ := os.Create(bundlePath)
+	gzipWriter := gzip.NewWriter(bundleFile)
+	tar.NewWriter(gzipWriter).Close()
+	gzipWriter.Close()
+	bundleFile.Close()
+
+	_, err := readBundleManifest(bundlePath)
+	assert.EqualError(t, err, textBundleNoManifest)
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
@@ -34,9 +34,10 @@ import (
 type (
 	// ValidationResponse represents the response to validating a project on the users filesystem.
 	ValidationResponse struct {
-		Status string      `json:"status"`
-		Path   string      `json:"projectPath"`
-		Result interface{} `json:"result"`
+		Status           string               `json:"status"`
+		Path             string               `json:"projectPath"`
+		Result           interface{}          `json:"result"`
+		ExtensionCommand *utils.CommandResult `json:"extensionCommand,omitempty"`
 	}
 
 	// CWSettings represents the .cw-settings file which is written to a project
@@ -51,6 +52,12 @@ type (
 		MavenProperties   []string `json:"mavenProperties,omitempty"`
 		StatusPingTimeout string   `json:"statusPingTimeout"`
 	}
+
+	// CreateResponse represents the response to creating a project and, optionally, binding it
+	CreateResponse struct {
+		Validation *ValidationResponse `json:"validation"`
+		Bind       *BindResponse       `json:"bind,omitempty"`
+	}
 )
 
 // DownloadTemplate using the url/link provided
@@ -60,12 +67,7 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 		return nil, projErr
 	}
 
-	projectDir := path.Base(destination)
-
-	// Remove invalid characters from the string we will use
-	// as the project name in the template.
-	r := regexp.MustCompile("[^a-zA-Z0-9._-]")
-	projectName := r.ReplaceAllString(projectDir, "")
+	projectName := ProjectNameFromPath(destination)
 	if len(projectName) == 0 {
 		projectName = "PROJ_NAME_PLACEHOLDER"
 	}
@@ -89,16 +91,40 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 	return &response, nil
 }
 
+// ProjectNameFromPath returns the name a project at the given path will be created with,
+// which is the project directory with any invalid characters removed
+func ProjectNameFromPath(projectPath string) string {
+	projectDir := path.Base(projectPath)
+	r := regexp.MustCompile("[^a-zA-Z0-9._-]")
+	return r.ReplaceAllString(projectDir, "")
+}
+
+// CreateAndBind binds a newly created project to the given connection, using the
+// language and project type from its validation result
+func CreateAndBind(validation *ValidationResponse, conID string) (*CreateResponse, *ProjectError) {
+	response := CreateResponse{Validation: validation}
+	projectType, ok := validation.Result.(ProjectType)
+	if validation.Status != "success" || !ok {
+		err := errors.New(textValidationFailed)
+		return &response, &ProjectError{errOpBind, err, err.Error()}
+	}
+
+	name := ProjectNameFromPath(validation.Path)
+	bindResponse, bindErr := Bind(validation.Path, name, projectType.Language, projectType.BuildType, conID)
+	response.Bind = bindResponse
+	return &response, bindErr
+}
+
 // checkIsExtension checks if a project is an extension project and run associated commands as necessary
-func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error) {
+func checkIsExtension(conID, projectPath string, c *cli.Context) (string, *utils.CommandResult, error) {
 	extensions, err := apiroutes.GetExtensions(conID)
 	if err != nil {
 		log.Println("There was a problem retrieving extensions data")
-		return "unknown", err
+		return "unknown", nil, err
 	}
 
 	params := make(map[string]string)
-	commandName := "postProjectValidate"
+	commandName := utils.CommandPostProjectValidate
 
 	// determine if type:subtype hint was given
 	// but only if url was not given
@@ -108,42 +134,42 @@ func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error)
 		if len(parts) > 1 {
 			params["$subtype"] = parts[1]
 		}
-		commandName = "postProjectValidateWithType"
-	}
-
-	for _, extension := range extensions {
-
-		var isMatch bool
-		if len(params) > 0 {
-			// check if extension project type matched the hinted type
-			isMatch = extension.ProjectType == params["$type"]
-		} else {
-			// check if project contains the detection file an extension defines
-			isMatch = extension.Detection != "" && utils.PathExists(path.Join(projectPath, extension.Detection))
-		}
-
-		if isMatch {
-			var cmdErr error
-			// check if there are any commands to run
-			for _, command := range extension.Commands {
-				if command.Name == commandName {
-					cmdErr = utils.RunCommand(projectPath, command, params)
-					break
-				}
+		commandName = utils.CommandPostProjectValidateWithType
+	}
+
+	// check if extension project type matched the hinted type, or
+	// if project contains the detection file an extension defines
+	extension, _ := utils.MatchExtension(extensions, projectPath, params["$type"])
+	if extension != nil {
+		var cmdResult *utils.CommandResult
+		var cmdErr error
+		// check if there are any commands to run
+		command := utils.FindCommand(*extension, commandName)
+		if command != nil {
+			options := utils.CommandOptions{
+				Env: map[string]string{
+					utils.EnvProjectPath:    projectPath,
+					utils.EnvConnectionID:   conID,
+					utils.EnvProjectType:    extension.ProjectType,
+					utils.EnvProjectSubtype: params["$subtype"],
+				},
+				JSONOutput: c.GlobalBool("json"),
 			}
-
-			return extension.ProjectType, cmdErr
+			cmdResult, cmdErr = utils.RunCommand(projectPath, *command, params, options)
 		}
+
+		return extension.ProjectType, cmdResult, cmdErr
 	}
 
-	return "", nil
+	return "", nil, nil
 }
 
 // ValidateProject returns the language and buildType for a project at given filesystem path,
-// and writes a default .cw-settings file to that project
+// and writes a default .cw-settings file to that project. If an extension's command fails, the
+// failed validation response is returned along with the error.
 func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
 	projectPath := c.String("path")
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	conID := connections.ResolveConnectionID(c.String("conid"))
 	projErr := checkProjectPathExists(projectPath)
 	if projErr != nil {
 		return nil, projErr
@@ -158,7 +184,7 @@ func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
 		BuildType: buildType,
 	}
 
-	extensionType, err := checkIsExtension(conID, projectPath, c)
+	extensionType, cmdResult, err := checkIsExtension(conID, projectPath, c)
 	if extensionType != "" {
 		if err == nil {
 			validationResult = ProjectType{
@@ -172,13 +198,15 @@ func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
 	}
 
 	response := ValidationResponse{
-		Status: validationStatus,
-		Path:   projectPath,
-		Result: validationResult,
+		Status:           validationStatus,
+		Path:             projectPath,
+		Result:           validationResult,
+		ExtensionCommand: cmdResult,
 	}
 
 	if err != nil {
-		return nil, &ProjectError{errOpCreateProject, err, err.Error()}
+		// the response says which extension command failed and what it output
+		return &response, &ProjectError{errOpCreateProject, err, err.Error()}
 	}
 
 	writeErr := writeCwSettingsIfNotInProject(conID, projectPath, buildType)
@@ -364,9 +392,11 @@ func getDefaultCwSettings(httpClient utils.HTTPClient, connection *connections.C
 	}, nil
 }
 
+// project types that support settings which only apply to some project types
+var projectTypesWithInternalDebugPort = []string{"liberty", "spring", "nodejs"}
+var projectTypesWithMavenSettings = []string{"liberty", "spring"}
+
 func addNonDefaultFieldsToCwSettings(cwSettings CWSettings, ProjectType string) CWSettings {
-	projectTypesWithInternalDebugPort := []string{"liberty", "spring", "nodejs"}
-	projectTypesWithMavenSettings := []string{"liberty", "spring"}
 	if stringInSlice(ProjectType, projectTypesWithInternalDebugPort) {
 		// We use a pointer, as an empty string would be removed due to omitempty on struct
 		defaultValue := ""
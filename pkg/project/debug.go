@@ -0,0 +1,110 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// DebugTarget : Where a debugger can attach to a project
+type DebugTarget struct {
+	ProjectID     string `json:"projectID"`
+	ConnectionID  string `json:"connectionID"`
+	Host          string `json:"host"`
+	Port          string `json:"port"`
+	Restarted     bool   `json:"restarted"`
+	PortForwarded bool   `json:"portForwarded"`
+}
+
+const startModeDebug = "debug"
+
+// PrepareDebug restarts a project in debug mode, unless it is already running in a debug mode, and waits for
+// it to start using the timeout and interval of the wait options. It returns the started project and whether
+// it had to be restarted.
+func PrepareDebug(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string, options WaitOptions, handler func(ProjectStatus)) (*Project, bool, *ProjectError) {
+	project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, false, projErr
+	}
+
+	restarted := false
+	if project.StartMode != startModeDebug && project.StartMode != "debugNoInit" {
+		err := RestartProject(httpClient, conInfo, conURL, projectID, startModeDebug)
+		if err != nil {
+			return nil, false, &ProjectError{errOpDebug, err, err.Error()}
+		}
+		restarted = true
+		options.For, options.StartMode = waitForStarted, startModeDebug
+		_, projErr = WaitForProject(httpClient, conInfo, conURL, projectID, options, handler)
+		if projErr != nil {
+			return nil, restarted, projErr
+		}
+		project, projErr = GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return nil, restarted, projErr
+		}
+	}
+
+	if project.Ports == nil || project.Ports.InternalDebugPort == "" {
+		err := errors.New(textNoDebugPort)
+		return nil, restarted, &ProjectError{errOpDebug, err, err.Error()}
+	}
+	return project, restarted, nil
+}
+
+// LocalDebugAddress returns the host and port that docker publishes the debug port of a local project on
+func LocalDebugAddress(project *Project) (string, string, *ProjectError) {
+	if project.ContainerID == "" {
+		err := errors.New(textNoProjectContainer)
+		return "", "", &ProjectError{errOpDebug, err, err.Error()}
+	}
+	dockerClient, dockerErr := docker.NewDockerClient()
+	if dockerErr != nil {
+		return "", "", &ProjectError{errOpDebug, dockerErr.Err, dockerErr.Desc}
+	}
+	container, dockerErr := docker.InspectContainer(dockerClient, project.ContainerID)
+	if dockerErr != nil {
+		return "", "", &ProjectError{errOpDebug, dockerErr.Err, dockerErr.Desc}
+	}
+	host, port := publishedPort(container, string(project.Ports.InternalDebugPort))
+	if port == "" {
+		// Codewind reports the published port too, for containers that cannot be inspected
+		host, port = "localhost", string(project.Ports.ExposedDebugPort)
+	}
+	if port == "" {
+		err := errors.New(textDebugPortNotPublished)
+		return "", "", &ProjectError{errOpDebug, err, err.Error()}
+	}
+	return host, port, nil
+}
+
+// publishedPort returns the host address that a container port is published on
+func publishedPort(container types.ContainerJSON, containerPort string) (string, string) {
+	if container.NetworkSettings == nil {
+		return "", ""
+	}
+	bindings := container.NetworkSettings.Ports[nat.Port(containerPort+"/tcp")]
+	if len(bindings) == 0 {
+		return "", ""
+	}
+	host := bindings[0].HostIP
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+	return host, bindings[0].HostPort
+}
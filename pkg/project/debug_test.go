@@ -0,0 +1,117 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockDebugRestart accepts restart requests, recording their start mode, and answers
+// other requests like clientMockProjectStates
+type clientMockDebugRestart struct {
+	clientMockProjectStates
+	restartModes []string
+}
+
+func (c *clientMockDebugRestart) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost {
+		return c.clientMockProjectStates.Do(req)
+	}
+	parameters := RestartParameters{}
+	json.NewDecoder(req.Body).Decode(&parameters)
+	c.restartModes = append(c.restartModes, parameters.StartMode)
+	return &http.Response{
+		StatusCode: http.StatusAccepted,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+	}, nil
+}
+
+func TestPrepareDebug(t *testing.T) {
+	mockConnection := connections.Connection{ID: "local"}
+	debugging := `{"projectID": "mockID", "appStatus": "started", "startMode": "debug", "ports": {"internalDebugPort": "7777"}}`
+	noDebugPort := `{"projectID": "mockID", "appStatus": "started", "startMode": "debugNoInit"}`
+	debugOptions := WaitOptions{Timeout: time.Second, Interval: time.Millisecond}
+
+	t.Run("success case: project already in debug mode is not restarted", func(t *testing.T) {
+		mockClient := &clientMockProjectStates{bodies: []string{debugging}}
+		project, restarted, projErr := PrepareDebug(mockClient, &mockConnection, "dummyurl", "mockID", debugOptions, func(ProjectStatus) {})
+		assert.Nil(t, projErr)
+		assert.False(t, restarted)
+		assert.Equal(t, PortNumber("7777"), project.Ports.InternalDebugPort)
+		assert.Equal(t, 1, mockClient.calls)
+	})
+	t.Run("success case: project is restarted and waited for until it starts in debug mode", func(t *testing.T) {
+		running := `{"projectID": "mockID", "appStatus": "started", "startMode": "run"}`
+		stopping := `{"projectID": "mockID", "appStatus": "stopping", "startMode": "run"}`
+		starting := `{"projectID": "mockID", "appStatus": "starting", "startMode": "debug"}`
+		mockClient := &clientMockDebugRestart{clientMockProjectStates: clientMockProjectStates{bodies: []string{running, running, stopping, starting, debugging}}}
+		transitions := []ProjectStatus{}
+		project, restarted, projErr := PrepareDebug(mockClient, &mockConnection, "dummyurl", "mockID", debugOptions, func(status ProjectStatus) {
+			transitions = append(transitions, status)
+		})
+		assert.Nil(t, projErr)
+		assert.True(t, restarted)
+		assert.Equal(t, []string{startModeDebug}, mockClient.restartModes)
+		// the project is still started in run mode when first polled, which must not end the wait
+		assert.Len(t, transitions, 4)
+		assert.Equal(t, "started", transitions[3].AppStatus)
+		assert.Equal(t, PortNumber("7777"), project.Ports.InternalDebugPort)
+	})
+	t.Run("fail case: project without a debug port", func(t *testing.T) {
+		mockClient := &clientMockProjectStates{bodies: []string{noDebugPort}}
+		_, _, projErr := PrepareDebug(mockClient, &mockConnection, "dummyurl", "mockID", debugOptions, func(ProjectStatus) {})
+		assert.Equal(t, errOpDebug, projErr.Op)
+		assert.Equal(t, textNoDebugPort, projErr.Desc)
+	})
+}
+
+func TestPublishedPort(t *testing.T) {
+	tests := map[string]struct {
+		bindings []nat.PortBinding
+		wantHost string
+		wantPort string
+	}{
+		"published on all interfaces": {
+			bindings: []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}},
+			wantHost: "localhost",
+			wantPort: "32768",
+		},
+		"published on one interface": {
+			bindings: []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "32769"}},
+			wantHost: "127.0.0.1",
+			wantPort: "32769",
+		},
+		"not published": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			container := types.ContainerJSON{NetworkSettings: &types.NetworkSettings{}}
+			container.NetworkSettings.Ports = nat.PortMap{}
+			if test.bindings != nil {
+				container.NetworkSettings.Ports["7777/tcp"] = test.bindings
+			}
+			host, port := publishedPort(container, "7777")
+			assert.Equal(t, test.wantHost, host)
+			assert.Equal(t, test.wantPort, port)
+		})
+	}
+}
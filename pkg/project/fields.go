@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+)
+
+// ProjectFilter matches projects on the value of one of their fields
+type ProjectFilter struct {
+	Field   string
+	Pattern string // shell pattern matched against the field value, e.g. node*
+	Negate  bool
+}
+
+// ProjectFieldValue returns the value of a project field, given its JSON name. Nested fields are
+// separated by dots, e.g. ports.exposedPort, and fields Codewind returned outside of the model can also be used.
+func ProjectFieldValue(project Project, field string) (interface{}, bool) {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return nil, false
+	}
+	var value interface{}
+	json.Unmarshal(data, &value)
+	for _, name := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// FormatFieldValue returns a field value as text, for printing and filtering
+func FormatFieldValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		text, _ := json.Marshal(value)
+		return string(text)
+	}
+}
+
+// SelectProjectFields returns the given fields of a project, keyed by field name. Fields the project does not have are nil.
+func SelectProjectFields(project Project, fields []string) map[string]interface{} {
+	selected := map[string]interface{}{}
+	for _, field := range fields {
+		value, _ := ProjectFieldValue(project, field)
+		selected[field] = value
+	}
+	return selected
+}
+
+// ParseProjectFilter parses a filter of the form field=pattern or field!=pattern
+func ParseProjectFilter(filter string) (*ProjectFilter, *ProjectError) {
+	separator, negate := "=", false
+	if strings.Contains(filter, "!=") {
+		separator, negate = "!=", true
+	}
+	parts := strings.SplitN(filter, separator, 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		err := errors.New(textInvalidFilter + ": " + filter)
+		return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if _, err := path.Match(parts[1], ""); err != nil {
+		err = errors.New(textInvalidFilter + ": " + filter)
+		return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	return &ProjectFilter{Field: strings.TrimSpace(parts[0]), Pattern: parts[1], Negate: negate}, nil
+}
+
+// Matches reports whether a project passes the filter. A missing field has an empty value.
+func (filter ProjectFilter) Matches(project Project) bool {
+	value, _ := ProjectFieldValue(project, filter.Field)
+	matched, _ := path.Match(filter.Pattern, FormatFieldValue(value))
+	return matched != filter.Negate
+}
+
+// FilterProjects returns the projects that pass every filter
+func FilterProjects(projects []Project, filters []ProjectFilter) []Project {
+	filtered := []Project{}
+	for _, project := range projects {
+		matched := true
+		for _, filter := range filters {
+			if !filter.Matches(project) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
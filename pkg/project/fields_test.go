@@ -0,0 +1,88 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectFields(t *testing.T) {
+	project := Project{
+		ProjectID: "1234",
+		Name:      "node-api",
+		AppStatus: "started",
+		Ports:     &ProjectPorts{ExposedPort: "32768"},
+		Extra:     map[string]json.RawMessage{"extension": json.RawMessage(`{"name":"appsodyExtension","config":{"style":"cli"}}`)},
+	}
+
+	t.Run("success case: fields are selected by name and path", func(t *testing.T) {
+		fields := SelectProjectFields(project, []string{"name", "ports.exposedPort", "extension.config.style", "missing"})
+		assert.Equal(t, map[string]interface{}{
+			"name":                   "node-api",
+			"ports.exposedPort":      "32768",
+			"extension.config.style": "cli",
+			"missing":                nil,
+		}, fields)
+	})
+
+	t.Run("success case: values are formatted as text", func(t *testing.T) {
+		value, _ := ProjectFieldValue(project, "extension.config")
+		assert.Equal(t, `{"style":"cli"}`, FormatFieldValue(value))
+		assert.Equal(t, "", FormatFieldValue(nil))
+	})
+}
+
+func TestFilterProjects(t *testing.T) {
+	projects := []Project{
+		{ProjectID: "1", Name: "node-api", AppStatus: "started", Ports: &ProjectPorts{ExposedPort: "32768"}},
+		{ProjectID: "2", Name: "node-web", AppStatus: "stopped"},
+		{ProjectID: "3", Name: "java-api", AppStatus: "started"},
+	}
+
+	tests := map[string]struct {
+		filters []string
+		wantIDs []string
+		wantOp  string
+	}{
+		"success case: equal":                  {filters: []string{"appStatus=started"}, wantIDs: []string{"1", "3"}},
+		"success case: not equal":              {filters: []string{"appStatus!=started"}, wantIDs: []string{"2"}},
+		"success case: pattern":                {filters: []string{"name=*-api"}, wantIDs: []string{"1", "3"}},
+		"success case: filters are combined":   {filters: []string{"name=node-*", "appStatus=started"}, wantIDs: []string{"1"}},
+		"success case: nested field":           {filters: []string{"ports.exposedPort=32768"}, wantIDs: []string{"1"}},
+		"success case: missing field is empty": {filters: []string{"ports.exposedPort="}, wantIDs: []string{"2", "3"}},
+		"fail case: no operator":               {filters: []string{"appStatus"}, wantOp: ErrOpInvalidOptions},
+		"fail case: no field":                  {filters: []string{"=started"}, wantOp: ErrOpInvalidOptions},
+		"fail case: invalid pattern":           {filters: []string{"name=node-["}, wantOp: ErrOpInvalidOptions},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filters := []ProjectFilter{}
+			for _, text := range test.filters {
+				filter, projErr := ParseProjectFilter(text)
+				if projErr != nil {
+					assert.Equal(t, test.wantOp, projErr.Op)
+					return
+				}
+				filters = append(filters, *filter)
+			}
+			assert.Equal(t, "", test.wantOp)
+			ids := []string{}
+			for _, project := range FilterProjects(projects, filters) {
+				ids = append(ids, project.ProjectID)
+			}
+			assert.Equal(t, test.wantIDs, ids)
+		})
+	}
+}
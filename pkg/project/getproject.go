@@ -1,10 +1,16 @@
 package project
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
@@ -12,17 +18,130 @@ import (
 )
 
 type (
-	// Project : Represents a project
+	// Project : Represents a project. Fields returned by Codewind that are not part of the model are kept in Extra,
+	// and are written back out when the project is marshalled.
 	Project struct {
-		ProjectID      string `json:"projectID"`
-		Name           string `json:"name"`
-		Language       string `json:"language"`
-		Host           string `json:"host"`
-		LocationOnDisk string `json:"locOnDisk"`
-		AppStatus      string `json:"appStatus"`
+		ProjectID           string        `json:"projectID"`
+		Name                string        `json:"name"`
+		Language            string        `json:"language"`
+		ProjectType         string        `json:"projectType"`
+		Host                string        `json:"host"`
+		LocationOnDisk      string        `json:"locOnDisk"`
+		AppStatus           string        `json:"appStatus"`
+		BuildStatus         string        `json:"buildStatus"`
+		ContainerID         string        `json:"containerId"`
+		DetailedBuildStatus string        `json:"detailedBuildStatus,omitempty"`
+		StartMode           string        `json:"startMode,omitempty"`
+		State               string        `json:"state,omitempty"`
+		PodName             string        `json:"podName,omitempty"`
+		AppBaseURL          string        `json:"appBaseURL,omitempty"`
+		ContextRoot         string        `json:"contextRoot,omitempty"`
+		HealthCheck         string        `json:"healthCheck,omitempty"`
+		IsHTTPS             *bool         `json:"isHttps,omitempty"`
+		AutoBuild           *bool         `json:"autoBuild,omitempty"`
+		InjectMetrics       *bool         `json:"injectMetrics,omitempty"`
+		MetricsAvailable    *bool         `json:"metricsAvailable,omitempty"`
+		CapabilitiesReady   *bool         `json:"capabilitiesReady,omitempty"`
+		CreationTime        int64         `json:"creationTime,omitempty"`
+		LastBuild           int64         `json:"lastbuild,omitempty"`
+		Ports               *ProjectPorts `json:"ports,omitempty"`
+
+		Extra map[string]json.RawMessage `json:"-"`
+	}
+
+	// ProjectPorts : The ports a project's application listens on, inside and outside of its container
+	ProjectPorts struct {
+		ExposedPort       PortNumber `json:"exposedPort,omitempty"`
+		InternalPort      PortNumber `json:"internalPort,omitempty"`
+		ExposedDebugPort  PortNumber `json:"exposedDebugPort,omitempty"`
+		InternalDebugPort PortNumber `json:"internalDebugPort,omitempty"`
 	}
+
+	// PortNumber : A port, which Codewind may send as either a string or a number
+	PortNumber string
+
+	// projectFields has the fields of a Project without its JSON methods
+	projectFields Project
 )
 
+// UnmarshalJSON decodes a project, keeping any fields that are not part of the model in Extra
+func (p *Project) UnmarshalJSON(data []byte) error {
+	var fields projectFields
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return err
+	}
+	for _, name := range modelFieldNames() {
+		delete(all, name)
+	}
+	*p = Project(fields)
+	if len(all) > 0 {
+		p.Extra = all
+	}
+	return nil
+}
+
+// MarshalJSON encodes a project, including the fields in Extra
+func (p Project) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(projectFields(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
+	}
+	names := []string{}
+	for name := range p.Extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, name := range names {
+		key, _ := json.Marshal(name)
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(p.Extra[name])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON accepts a port given as a string or a number
+func (port *PortNumber) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case string:
+		*port = PortNumber(value)
+	case float64:
+		*port = PortNumber(strconv.FormatFloat(value, 'f', -1, 64))
+	case nil:
+		*port = ""
+	default:
+		return fmt.Errorf("port must be a string or a number: %s", data)
+	}
+	return nil
+}
+
+// modelFieldNames returns the JSON names of the fields of the project model
+func modelFieldNames() []string {
+	names := []string{}
+	modelType := reflect.TypeOf(Project{})
+	for i := 0; i < modelType.NumField(); i++ {
+		name := strings.Split(modelType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // GetProjectFromID : Get project details from Codewind
 func GetProjectFromID(httpClient utils.HTTPClient, connection *connections.Connection, url, projectID string) (*Project, *ProjectError) {
 	req, requestErr := http.NewRequest("GET", url+"/api/v1/projects/"+projectID+"/", nil)
@@ -68,3 +68,43 @@ func TestGetProjectIDFromName(t *testing.T) {
 		assert.Equal(t, "9999", projectID)
 	})
 }
+
+func TestProjectJSON(t *testing.T) {
+	pfeProject := `{"projectID":"1234","name":"App1","language":"nodejs","buildStatus":"success","startMode":"run",` +
+		`"autoBuild":false,"ports":{"exposedPort":"32768","internalPort":3000},"creationTime":1580000000000,` +
+		`"extension":{"name":"appsodyExtension"},"links":{"_links":[]}}`
+
+	t.Run("success case: model fields are decoded and unknown fields are kept", func(t *testing.T) {
+		var project Project
+		err := json.Unmarshal([]byte(pfeProject), &project)
+		assert.Nil(t, err)
+		assert.Equal(t, "success", project.BuildStatus)
+		assert.Equal(t, "run", project.StartMode)
+		assert.False(t, *project.AutoBuild)
+		assert.Equal(t, PortNumber("32768"), project.Ports.ExposedPort)
+		assert.Equal(t, PortNumber("3000"), project.Ports.InternalPort)
+		assert.Equal(t, int64(1580000000000), project.CreationTime)
+		assert.Equal(t, map[string]json.RawMessage{
+			"extension": json.RawMessage(`{"name":"appsodyExtension"}`),
+			"links":     json.RawMessage(`{"_links":[]}`),
+		}, project.Extra)
+	})
+
+	t.Run("success case: unknown fields are written back out", func(t *testing.T) {
+		var project Project
+		json.Unmarshal([]byte(pfeProject), &project)
+		data, err := json.Marshal(project)
+		assert.Nil(t, err)
+		var roundTrip Project
+		err = json.Unmarshal(data, &roundTrip)
+		assert.Nil(t, err)
+		assert.Equal(t, project, roundTrip)
+		assert.Contains(t, string(data), `"extension":{"name":"appsodyExtension"}`)
+	})
+
+	t.Run("success case: a project without unknown fields has no extra fields", func(t *testing.T) {
+		var project Project
+		json.Unmarshal([]byte(`{"projectID":"1234","name":"App1"}`), &project)
+		assert.Nil(t, project.Extra)
+	})
+}
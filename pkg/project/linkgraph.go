@@ -0,0 +1,238 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LinkGraph : The links between all of the projects on a connection, and any problems with them
+	LinkGraph struct {
+		ConnectionID      string             `json:"connectionID"`
+		Projects          []GraphProject     `json:"projects"`
+		Links             []GraphLink        `json:"links"`
+		DanglingLinks     []GraphLink        `json:"danglingLinks"`
+		DuplicateEnvNames []DuplicateEnvName `json:"duplicateEnvNames"`
+		Cycles            [][]string         `json:"cycles"`
+	}
+
+	// GraphProject : A project in a link graph
+	GraphProject struct {
+		ProjectID string `json:"projectID"`
+		Name      string `json:"name"`
+	}
+
+	// GraphLink : A link from one project to another, exposed to the source project as an environment variable
+	GraphLink struct {
+		SourceProjectID string `json:"sourceProjectID"`
+		TargetProjectID string `json:"targetProjectID"`
+		TargetName      string `json:"targetName"`
+		EnvName         string `json:"envName"`
+	}
+
+	// DuplicateEnvName : An environment variable name used by more than one link of a project
+	DuplicateEnvName struct {
+		ProjectID        string   `json:"projectID"`
+		EnvName          string   `json:"envName"`
+		TargetProjectIDs []string `json:"targetProjectIDs"`
+	}
+)
+
+// BuildLinkGraph gets the links of every project on a connection and checks them for links to removed
+// projects, environment variable names used by more than one link and cycles
+func BuildLinkGraph(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string) (*LinkGraph, *ProjectError) {
+	projects, projErr := GetAll(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+	projectLinks := map[string][]Link{}
+	for _, project := range projects {
+		links, projErr := GetProjectLinks(httpClient, conInfo, conURL, project.ProjectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		projectLinks[project.ProjectID] = links
+	}
+	graph := newLinkGraph(projects, projectLinks)
+	graph.ConnectionID = conInfo.ID
+	return graph, nil
+}
+
+// newLinkGraph builds a link graph from projects and the links of each of them
+func newLinkGraph(projects []Project, projectLinks map[string][]Link) *LinkGraph {
+	graph := &LinkGraph{
+		Projects:          []GraphProject{},
+		Links:             []GraphLink{},
+		DanglingLinks:     []GraphLink{},
+		DuplicateEnvNames: []DuplicateEnvName{},
+		Cycles:            [][]string{},
+	}
+	sorted := append([]Project{}, projects...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	known := map[string]bool{}
+	for _, project := range sorted {
+		graph.Projects = append(graph.Projects, GraphProject{project.ProjectID, project.Name})
+		known[project.ProjectID] = true
+	}
+
+	edges := map[string][]string{}
+	for _, project := range graph.Projects {
+		targetsByEnv := map[string][]string{}
+		envNames := []string{}
+		for _, link := range projectLinks[project.ProjectID] {
+			graphLink := GraphLink{project.ProjectID, link.ProjectID, link.ProjectName, link.EnvName}
+			graph.Links = append(graph.Links, graphLink)
+			if !known[link.ProjectID] {
+				graph.DanglingLinks = append(graph.DanglingLinks, graphLink)
+			} else {
+				edges[project.ProjectID] = append(edges[project.ProjectID], link.ProjectID)
+			}
+			if _, seen := targetsByEnv[link.EnvName]; !seen {
+				envNames = append(envNames, link.EnvName)
+			}
+			targetsByEnv[link.EnvName] = append(targetsByEnv[link.EnvName], link.ProjectID)
+		}
+		for _, envName := range envNames {
+			if len(targetsByEnv[envName]) > 1 {
+				graph.DuplicateEnvNames = append(graph.DuplicateEnvNames, DuplicateEnvName{project.ProjectID, envName, targetsByEnv[envName]})
+			}
+		}
+	}
+
+	projectIDs := []string{}
+	for _, project := range graph.Projects {
+		projectIDs = append(projectIDs, project.ProjectID)
+	}
+	graph.Cycles = findCycles(projectIDs, edges)
+	return graph
+}
+
+// findCycles returns the groups of projects that are linked in a cycle, found as the strongly connected
+// components of the graph (Tarjan's algorithm) that have more than one project or link to themselves
+func findCycles(nodes []string, edges map[string][]string) [][]string {
+	index := 0
+	indexes := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+
+	var connect func(node string)
+	connect = func(node string) {
+		indexes[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range edges[node] {
+			if _, visited := indexes[next]; !visited {
+				connect(next)
+				if lowLinks[next] < lowLinks[node] {
+					lowLinks[node] = lowLinks[next]
+				}
+			} else if onStack[next] && indexes[next] < lowLinks[node] {
+				lowLinks[node] = indexes[next]
+			}
+		}
+
+		if lowLinks[node] == indexes[node] {
+			component := []string{}
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			if len(component) > 1 || linksTo(edges[node], node) {
+				sort.Strings(component)
+				cycles = append(cycles, component)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := indexes[node]; !visited {
+			connect(node)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+func linksTo(targets []string, node string) bool {
+	for _, target := range targets {
+		if target == node {
+			return true
+		}
+	}
+	return false
+}
+
+// DOT returns the link graph in the Graphviz DOT language. Links to removed projects are dashed,
+// links that share an environment variable name are orange and projects linked in a cycle are red.
+func (graph *LinkGraph) DOT() string {
+	inCycle := map[string]bool{}
+	for _, cycle := range graph.Cycles {
+		for _, projectID := range cycle {
+			inCycle[projectID] = true
+		}
+	}
+	duplicate := map[string]bool{}
+	for _, dup := range graph.DuplicateEnvNames {
+		duplicate[dup.ProjectID+"/"+dup.EnvName] = true
+	}
+	dangling := map[string]bool{}
+	for _, link := range graph.DanglingLinks {
+		dangling[link.TargetProjectID] = true
+	}
+
+	var dot strings.Builder
+	dot.WriteString("digraph links {\n")
+	for _, project := range graph.Projects {
+		attributes := "label=" + strconv.Quote(project.Name)
+		if inCycle[project.ProjectID] {
+			attributes += ", color=red"
+		}
+		fmt.Fprintf(&dot, "  %s [%s];\n", strconv.Quote(project.ProjectID), attributes)
+	}
+	written := map[string]bool{}
+	for _, link := range graph.DanglingLinks {
+		if !written[link.TargetProjectID] {
+			written[link.TargetProjectID] = true
+			label := link.TargetName + " (removed)"
+			fmt.Fprintf(&dot, "  %s [label=%s, style=dashed];\n", strconv.Quote(link.TargetProjectID), strconv.Quote(label))
+		}
+	}
+	for _, link := range graph.Links {
+		attributes := "label=" + strconv.Quote(link.EnvName)
+		if dangling[link.TargetProjectID] {
+			attributes += ", style=dashed"
+		}
+		if duplicate[link.SourceProjectID+"/"+link.EnvName] {
+			attributes += ", color=orange"
+		}
+		fmt.Fprintf(&dot, "  %s -> %s [%s];\n", strconv.Quote(link.SourceProjectID), strconv.Quote(link.TargetProjectID), attributes)
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
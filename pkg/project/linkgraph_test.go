@@ -0,0 +1,101 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLinkGraph(t *testing.T) {
+	projects := []Project{
+		{ProjectID: "web", Name: "web"},
+		{ProjectID: "api", Name: "api"},
+		{ProjectID: "db", Name: "db"},
+		{ProjectID: "auth", Name: "auth"},
+	}
+	projectLinks := map[string][]Link{
+		"web": {
+			{ProjectID: "api", ProjectName: "api", EnvName: "API_URL"},
+			{ProjectID: "auth", ProjectName: "auth", EnvName: "API_URL"},
+		},
+		"api": {
+			{ProjectID: "db", ProjectName: "db", EnvName: "DB_URL"},
+			{ProjectID: "cache", ProjectName: "cache", EnvName: "CACHE_URL"},
+		},
+		"db": {
+			{ProjectID: "api", ProjectName: "api", EnvName: "API_URL"},
+		},
+		"auth": {
+			{ProjectID: "auth", ProjectName: "auth", EnvName: "SELF_URL"},
+		},
+	}
+
+	graph := newLinkGraph(projects, projectLinks)
+
+	t.Run("success case: projects are sorted by name and every link is included", func(t *testing.T) {
+		assert.Equal(t, []GraphProject{{"api", "api"}, {"auth", "auth"}, {"db", "db"}, {"web", "web"}}, graph.Projects)
+		assert.Len(t, graph.Links, 6)
+	})
+
+	t.Run("success case: the caller's projects are not reordered", func(t *testing.T) {
+		assert.Equal(t, "web", projects[0].ProjectID)
+		assert.Equal(t, "auth", projects[3].ProjectID)
+	})
+
+	t.Run("success case: links to removed projects are dangling", func(t *testing.T) {
+		assert.Equal(t, []GraphLink{{"api", "cache", "cache", "CACHE_URL"}}, graph.DanglingLinks)
+	})
+
+	t.Run("success case: env names used by more than one link are duplicates", func(t *testing.T) {
+		assert.Equal(t, []DuplicateEnvName{{"web", "API_URL", []string{"api", "auth"}}}, graph.DuplicateEnvNames)
+	})
+
+	t.Run("success case: cycles and self links are found", func(t *testing.T) {
+		assert.Equal(t, [][]string{{"api", "db"}, {"auth"}}, graph.Cycles)
+	})
+
+	t.Run("success case: graph is written as DOT", func(t *testing.T) {
+		dot := graph.DOT()
+		assert.Contains(t, dot, `"api" [label="api", color=red];`)
+		assert.Contains(t, dot, `"web" [label="web"];`)
+		assert.Contains(t, dot, `"cache" [label="cache (removed)", style=dashed];`)
+		assert.Contains(t, dot, `"api" -> "cache" [label="CACHE_URL", style=dashed];`)
+		assert.Contains(t, dot, `"web" -> "auth" [label="API_URL", color=orange];`)
+		assert.Contains(t, dot, `"api" -> "db" [label="DB_URL"];`)
+	})
+}
+
+func TestBuildLinkGraph(t *testing.T) {
+	t.Run("success case: connection without projects has an empty graph", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("[]")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: body}
+		mockConnection := connections.Connection{ID: "local"}
+		graph, projErr := BuildLinkGraph(mockClient, &mockConnection, "dummyurl")
+		assert.Nil(t, projErr)
+		assert.Equal(t, "local", graph.ConnectionID)
+		assert.Empty(t, graph.Projects)
+		assert.Empty(t, graph.Cycles)
+	})
+
+	t.Run("fail case: projects cannot be listed", func(t *testing.T) {
+		mockConnection := connections.Connection{ID: "local"}
+		_, projErr := BuildLinkGraph(&security.ClientMockRequestFail{}, &mockConnection, "dummyurl")
+		assert.NotNil(t, projErr)
+	})
+}
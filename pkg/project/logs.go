@@ -0,0 +1,405 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LogOptions selects the project logs to stream
+	LogOptions struct {
+		Type   string // "app" or "build"
+		Follow bool   // keep streaming new lines until interrupted
+		Since  string // only lines since a timestamp, or a duration such as 10m
+		Tail   string // only the given number of lines from the end of the log, or "all"
+	}
+
+	// LogLine is a line from a project log
+	LogLine struct {
+		ProjectID string `json:"projectID"`
+		Type      string `json:"type"`
+		Stream    string `json:"stream,omitempty"`
+		Log       string `json:"log,omitempty"`
+		Time      string `json:"time,omitempty"`
+		Line      string `json:"line"`
+	}
+)
+
+const (
+	logTypeApp   = "app"
+	logTypeBuild = "build"
+)
+
+// StreamProjectLogs calls the handler with each line of a project's build or app log. The app logs of
+// projects on the local connection are read from the project's container, other logs from the Codewind server.
+func StreamProjectLogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string, options LogOptions, handler func(LogLine)) *ProjectError {
+	projErr := validateLogOptions(&options)
+	if projErr != nil {
+		return projErr
+	}
+
+	if conInfo.ID == "local" && options.Type == logTypeApp {
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return projErr
+		}
+		if project.ContainerID == "" {
+			err := errors.New(textNoProjectContainer)
+			return &ProjectError{errOpLogs, err, err.Error()}
+		}
+		dockerClient, dockerErr := docker.NewDockerClient()
+		if dockerErr != nil {
+			return &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+		}
+		return streamContainerLogs(dockerClient, project.ContainerID, projectID, options, handler)
+	}
+	return streamPFELogs(httpClient, conInfo, conURL, projectID, options, handler)
+}
+
+func validateLogOptions(options *LogOptions) *ProjectError {
+	if options.Type == "" {
+		options.Type = logTypeApp
+	}
+	if options.Type != logTypeApp && options.Type != logTypeBuild {
+		err := fmt.Errorf("%s: %s", textInvalidLogType, options.Type)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if options.Tail == "" {
+		options.Tail = "all"
+	}
+	if tail, err := strconv.Atoi(options.Tail); options.Tail != "all" && (err != nil || tail < 0) {
+		err := fmt.Errorf("%s: %s", textInvalidLogTail, options.Tail)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if _, err := logSinceTime(options.Since, time.Now()); err != nil {
+		err := fmt.Errorf("%s: %s", textInvalidLogSince, options.Since)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	return nil
+}
+
+// logSinceTime returns the time a since option refers to, read as docker reads it, or the zero time when it is empty
+func logSinceTime(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	timestamp, err := timetypes.GetTimestamp(since, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, nanoseconds, err := timetypes.ParseTimestamps(timestamp, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanoseconds), nil
+}
+
+// streamContainerLogs reads the logs of a project container from docker
+func streamContainerLogs(dockerClient docker.DockerClient, containerID, projectID string, options LogOptions, handler func(LogLine)) *ProjectError {
+	container, dockerErr := docker.InspectContainer(dockerClient, containerID)
+	if dockerErr != nil {
+		return &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+	}
+
+	logStream, dockerErr := docker.GetContainerLogsWithOptions(dockerClient, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     options.Follow,
+		Since:      options.Since,
+		Tail:       options.Tail,
+	})
+	if dockerErr != nil {
+		return &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+	}
+	defer logStream.Close()
+
+	stdout := newLogLineWriter(projectID, options.Type, "stdout", true, handler)
+	stderr := newLogLineWriter(projectID, options.Type, "stderr", true, handler)
+	var err error
+	if container.Config != nil && container.Config.Tty {
+		// containers with a TTY do not multiplex their output
+		_, err = io.Copy(stdout, logStream)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, logStream)
+	}
+	stdout.Flush()
+	stderr.Flush()
+	if err != nil {
+		return &ProjectError{errOpLogs, err, err.Error()}
+	}
+	return nil
+}
+
+type (
+	// pfeLogFiles is the response of the Codewind server's project logs API, listing the log files of each type
+	pfeLogFiles map[string][]struct {
+		Origin string   `json:"origin"`
+		Files  []string `json:"files"`
+	}
+
+	// pfeLogUpdate is the log-update event the Codewind server sends on its socket with new content of a log file.
+	// The first update for each file after streaming is turned on has the whole of the file.
+	pfeLogUpdate struct {
+		ProjectID string `json:"projectID"`
+		LogType   string `json:"logType"`
+		LogName   string `json:"logName"`
+		Logs      string `json:"logs"`
+		Reset     bool   `json:"reset"`
+	}
+)
+
+// logsIdleTimeout is how long to wait for the content of the next log file when not following the logs
+const logsIdleTimeout = 5 * time.Second
+
+// streamPFELogs reads project logs from the Codewind server. It lists the project's log files with
+// GET /api/v1/projects/{id}/logs, joins the server's socket, which is what the Codewind IDE plugins read logs from,
+// and turns on log streaming with POST /api/v1/projects/{id}/logs, which makes the server send the content of each
+// log file as log-update events. Streaming is left on when done, as other clients may be using it.
+// The server does not filter the logs by time, so lines before since are dropped here by their docker-style
+// timestamp. A line without one has the time of the last line before it that has one, or, in an update after the
+// first for its file, the time the update arrived, so lines of log files without timestamps that were written
+// before the logs were requested are left out.
+func streamPFELogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string, options LogOptions, handler func(LogLine)) *ProjectError {
+	since, err := logSinceTime(options.Since, time.Now())
+	if err != nil {
+		err := fmt.Errorf("%s: %s", textInvalidLogSince, options.Since)
+		return &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	logFiles := pfeLogFiles{}
+	projErr := requestPFELogs(httpClient, conInfo, conURL, projectID, "GET", &logFiles)
+	if projErr != nil {
+		return projErr
+	}
+	fileCount := 0
+	for _, origin := range logFiles[options.Type] {
+		fileCount += len(origin.Files)
+	}
+	if fileCount == 0 && !options.Follow {
+		return nil
+	}
+
+	namespace, projErr := getSocketNamespace(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return projErr
+	}
+	accessToken := ""
+	if !strings.EqualFold(conInfo.ID, "local") {
+		// the requests above went through the gatekeeper, so the keychain has a current access token
+		accessToken, _ = security.GetSecretFromKeyring(strings.ToLower(conInfo.ID), "access_token")
+	}
+	socket, err := dialPFESocket(conInfo, conURL, namespace, accessToken)
+	if err != nil {
+		return &ProjectError{errOpLogs, err, err.Error()}
+	}
+	defer socket.Close()
+	projErr = requestPFELogs(httpClient, conInfo, conURL, projectID, "POST", nil)
+	if projErr != nil {
+		return projErr
+	}
+
+	writers := map[string]*logLineWriter{}
+	defer func() {
+		for _, writer := range writers {
+			writer.Flush()
+		}
+	}()
+	for {
+		deadline := time.Time{}
+		if !options.Follow {
+			deadline = time.Now().Add(logsIdleTimeout)
+		}
+		event, data, err := socket.readEvent(deadline)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				// the server has sent all the log files it is going to
+				return nil
+			}
+			return &ProjectError{errOpLogs, err, err.Error()}
+		}
+		update := pfeLogUpdate{}
+		if event != "log-update" || json.Unmarshal(data, &update) != nil || update.ProjectID != projectID || update.LogType != options.Type {
+			continue
+		}
+		content := update.Logs
+		writer, seen := writers[update.LogName]
+		if !seen {
+			writer = newLogLineWriter(projectID, options.Type, "", false, handler)
+			writer.template.Log = update.LogName
+			writer.since = since
+			writers[update.LogName] = writer
+			content = tailLines(content, options.Tail)
+		} else {
+			writer.lineTime = time.Now()
+		}
+		writer.Write([]byte(content))
+		if !options.Follow && len(writers) >= fileCount {
+			return nil
+		}
+	}
+}
+
+// requestPFELogs sends a request to the project logs API of the Codewind server, decoding the response into result if it is not nil
+func requestPFELogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID, method string, result interface{}) *ProjectError {
+	req, err := http.NewRequest(method, conURL+"/api/v1/projects/"+projectID+"/logs", nil)
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		respErr := errors.New(textAPINotFound)
+		return &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	default:
+		respErr := fmt.Errorf("%s: %d", textUnknownResponseCode, resp.StatusCode)
+		return &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return &ProjectError{errOpResponse, err, err.Error()}
+	}
+	return nil
+}
+
+// getSocketNamespace returns the namespace of the Codewind server's socket, from its environment API
+func getSocketNamespace(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string) (string, *ProjectError) {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/environment", nil)
+	if err != nil {
+		return "", &ProjectError{errOpRequest, err, err.Error()}
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return "", &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respErr := fmt.Errorf("%s: %d", textUnknownResponseCode, resp.StatusCode)
+		return "", &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+	env := struct {
+		SocketNamespace string `json:"socket_namespace"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return "", &ProjectError{errOpResponse, err, err.Error()}
+	}
+	return env.SocketNamespace, nil
+}
+
+// tailLines returns the given number of lines from the end of a log, or all of it
+func tailLines(content string, tail string) string {
+	lines, err := strconv.Atoi(tail)
+	if err != nil {
+		return content
+	}
+	if lines <= 0 {
+		return ""
+	}
+	end := len(strings.TrimSuffix(content, "\n"))
+	start := end
+	for ; lines > 0 && start > 0; lines-- {
+		start = strings.LastIndexByte(content[:start], '\n')
+		if start < 0 {
+			return content
+		}
+	}
+	if lines > 0 {
+		return content
+	}
+	return content[start+1:]
+}
+
+// logLineWriter splits what is written to it into log lines, leaving out lines before since if it is set
+type logLineWriter struct {
+	template      LogLine
+	hasTimestamps bool
+	handler       func(LogLine)
+	partial       []byte
+	since         time.Time
+	lineTime      time.Time // the time of the last line with a timestamp, used for the lines without one
+}
+
+func newLogLineWriter(projectID, logType, stream string, hasTimestamps bool, handler func(LogLine)) *logLineWriter {
+	return &logLineWriter{
+		template:      LogLine{ProjectID: projectID, Type: logType, Stream: stream},
+		hasTimestamps: hasTimestamps,
+		handler:       handler,
+	}
+}
+
+func (lw *logLineWriter) Write(data []byte) (int, error) {
+	lw.partial = append(lw.partial, data...)
+	for {
+		lineEnd := bytes.IndexByte(lw.partial, '\n')
+		if lineEnd < 0 {
+			// keep any incomplete line until the rest is written
+			break
+		}
+		lw.writeLine(string(lw.partial[:lineEnd]))
+		lw.partial = lw.partial[lineEnd+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes any incomplete last line
+func (lw *logLineWriter) Flush() {
+	if len(lw.partial) > 0 {
+		lw.writeLine(string(lw.partial))
+		lw.partial = nil
+	}
+}
+
+func (lw *logLineWriter) writeLine(text string) {
+	line := lw.template
+	line.Line = strings.TrimSuffix(text, "\r")
+	// docker puts an RFC3339 timestamp and a space before each line, which other log files may also have
+	parts := strings.SplitN(line.Line, " ", 2)
+	if len(parts) == 2 {
+		lineTime, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err == nil {
+			lw.lineTime = lineTime
+		}
+		if err == nil || lw.hasTimestamps {
+			line.Time, line.Line = parts[0], parts[1]
+		}
+	}
+	if !lw.since.IsZero() && lw.lineTime.Before(lw.since) {
+		return
+	}
+	lw.handler(line)
+}
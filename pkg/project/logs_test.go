@@ -0,0 +1,277 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestValidateLogOptions(t *testing.T) {
+	tests := map[string]struct {
+		in     LogOptions
+		want   LogOptions
+		wantOp string
+	}{
+		"success case: defaults to all of the app log": {
+			in:   LogOptions{},
+			want: LogOptions{Type: "app", Tail: "all"},
+		},
+		"success case: build log tail": {
+			in:   LogOptions{Type: "build", Tail: "100", Since: "10m"},
+			want: LogOptions{Type: "build", Tail: "100", Since: "10m"},
+		},
+		"fail case: unknown log type": {
+			in:     LogOptions{Type: "debug"},
+			wantOp: ErrOpInvalidOptions,
+		},
+		"fail case: tail is not a number": {
+			in:     LogOptions{Tail: "last"},
+			wantOp: ErrOpInvalidOptions,
+		},
+		"fail case: tail is negative": {
+			in:     LogOptions{Tail: "-1"},
+			wantOp: ErrOpInvalidOptions,
+		},
+		"fail case: since is not a timestamp or duration": {
+			in:     LogOptions{Since: "yesterday"},
+			wantOp: ErrOpInvalidOptions,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			options := test.in
+			projErr := validateLogOptions(&options)
+			if test.wantOp != "" {
+				assert.Equal(t, test.wantOp, projErr.Op)
+			} else {
+				assert.Nil(t, projErr)
+				assert.Equal(t, test.want, options)
+			}
+		})
+	}
+}
+
+func TestLogLineWriter(t *testing.T) {
+	t.Run("success case: lines split across writes and timestamps removed", func(t *testing.T) {
+		lines := []LogLine{}
+		writer := newLogLineWriter("mockID", "app", "stdout", true, func(line LogLine) { lines = append(lines, line) })
+		writer.Write([]byte("2020-02-01T10:00:00.000000000Z Server start"))
+		writer.Write([]byte("ed\r\n2020-02-01T10:00:01.000000000Z Listening on port 3000\n2020-02-01T10:00:02.000000000Z partial"))
+		assert.Len(t, lines, 2)
+		writer.Flush()
+		assert.Equal(t, []LogLine{
+			{ProjectID: "mockID", Type: "app", Stream: "stdout", Time: "2020-02-01T10:00:00.000000000Z", Line: "Server started"},
+			{ProjectID: "mockID", Type: "app", Stream: "stdout", Time: "2020-02-01T10:00:01.000000000Z", Line: "Listening on port 3000"},
+			{ProjectID: "mockID", Type: "app", Stream: "stdout", Time: "2020-02-01T10:00:02.000000000Z", Line: "partial"},
+		}, lines)
+	})
+
+	t.Run("success case: lines without timestamps are unchanged", func(t *testing.T) {
+		lines := []LogLine{}
+		writer := newLogLineWriter("mockID", "build", "", false, func(line LogLine) { lines = append(lines, line) })
+		writer.Write([]byte("Step 1/5 : FROM node\n\nStep 2/5 : COPY . /app\n"))
+		writer.Flush()
+		assert.Equal(t, []string{"Step 1/5 : FROM node", "", "Step 2/5 : COPY . /app"}, []string{lines[0].Line, lines[1].Line, lines[2].Line})
+	})
+
+	t.Run("success case: lines before since are left out by their own or an earlier timestamp", func(t *testing.T) {
+		lines := []string{}
+		writer := newLogLineWriter("mockID", "build", "", false, func(line LogLine) { lines = append(lines, line.Line) })
+		writer.since = time.Date(2020, 2, 1, 10, 0, 1, 0, time.UTC)
+		writer.Write([]byte("no timestamp\n2020-02-01T10:00:00Z old\nold continued\n2020-02-01T10:00:02.5Z new\nnew continued\n"))
+		assert.Equal(t, []string{"new", "new continued"}, lines)
+	})
+}
+
+// mockPFELogs serves the parts of the Codewind server API used to read project logs: the environment,
+// the project logs API and a socket.io socket on the /default namespace that sends the given events
+// once log streaming is turned on
+func mockPFELogs(t *testing.T, events []string) *httptest.Server {
+	streaming := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/environment", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"socket_namespace": "/default"}`))
+	})
+	mux.HandleFunc("/api/v1/projects/mockID/logs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			close(streaming)
+			return
+		}
+		w.Write([]byte(`{"build": [{"origin": "workspace", "files": ["/codewind-workspace/.logs/mock/docker.build.log", "/codewind-workspace/.logs/mock/maven.build.log"]}], "app": []}`))
+	})
+	mux.Handle("/socket.io/", websocket.Handler(func(ws *websocket.Conn) {
+		assert.Equal(t, "EIO=3&transport=websocket", ws.Request().URL.RawQuery)
+		websocket.Message.Send(ws, `0{"sid": "mock", "upgrades": [], "pingInterval": 25000, "pingTimeout": 5000}`)
+		websocket.Message.Send(ws, "40")
+		var join string
+		websocket.Message.Receive(ws, &join)
+		assert.Equal(t, "40/default", join)
+		websocket.Message.Send(ws, "40/default")
+		<-streaming
+		for _, event := range events {
+			websocket.Message.Send(ws, event)
+		}
+		// keep the socket open until the client closes it
+		var packet string
+		for websocket.Message.Receive(ws, &packet) == nil {
+		}
+	}))
+	return httptest.NewServer(mux)
+}
+
+func TestStreamPFELogs(t *testing.T) {
+	mockConnection := connections.Connection{ID: "local"}
+	events := []string{
+		`42["log-update", {"projectID": "mockID", "logType": "build", "logName": "docker.build", "logs": "root namespace\n", "reset": true}]`,
+		`42/default,["log-update", {"projectID": "otherID", "logType": "build", "logName": "docker.build", "logs": "other project\n", "reset": true}]`,
+		`42/default,["log-update", {"projectID": "mockID", "logType": "app", "logName": "app", "logs": "app log\n", "reset": true}]`,
+		`42/default,["projectStatusChanged", {"projectID": "mockID", "appStatus": "started"}]`,
+		`42/default,["log-update", {"projectID": "mockID", "logType": "build", "logName": "docker.build", "logs": "Step 1/3\nStep 2/3\nStep 3/3\n", "reset": true}]`,
+		`42/default,["log-update", {"projectID": "mockID", "logType": "build", "logName": "maven.build", "logs": "BUILD SUCCESS\n", "reset": true}]`,
+	}
+
+	t.Run("success case: the last lines of each log file are read from the socket", func(t *testing.T) {
+		server := mockPFELogs(t, events)
+		defer server.Close()
+		lines := []LogLine{}
+		projErr := streamPFELogs(&http.Client{}, &mockConnection, server.URL, "mockID", LogOptions{Type: "build", Tail: "2"}, func(line LogLine) { lines = append(lines, line) })
+		assert.Nil(t, projErr)
+		assert.Equal(t, []LogLine{
+			{ProjectID: "mockID", Type: "build", Log: "docker.build", Line: "Step 2/3"},
+			{ProjectID: "mockID", Type: "build", Log: "docker.build", Line: "Step 3/3"},
+			{ProjectID: "mockID", Type: "build", Log: "maven.build", Line: "BUILD SUCCESS"},
+		}, lines)
+	})
+
+	t.Run("success case: a project without log files has no lines", func(t *testing.T) {
+		server := mockPFELogs(t, events)
+		defer server.Close()
+		lines := []LogLine{}
+		projErr := streamPFELogs(&http.Client{}, &mockConnection, server.URL, "mockID", LogOptions{Type: "app", Tail: "all"}, func(line LogLine) { lines = append(lines, line) })
+		assert.Nil(t, projErr)
+		assert.Empty(t, lines)
+	})
+
+	t.Run("success case: lines before since are left out", func(t *testing.T) {
+		server := mockPFELogs(t, []string{
+			`42/default,["log-update", {"projectID": "mockID", "logType": "build", "logName": "docker.build", "logs": "2020-02-01T10:00:00Z Step 1/3\n2020-02-01T10:05:00Z Step 2/3\n", "reset": true}]`,
+			`42/default,["log-update", {"projectID": "mockID", "logType": "build", "logName": "maven.build", "logs": "BUILD SUCCESS\n", "reset": true}]`,
+		})
+		defer server.Close()
+		lines := []LogLine{}
+		projErr := streamPFELogs(&http.Client{}, &mockConnection, server.URL, "mockID", LogOptions{Type: "build", Tail: "all", Since: "2020-02-01T10:01:00Z"}, func(line LogLine) { lines = append(lines, line) })
+		assert.Nil(t, projErr)
+		assert.Equal(t, []LogLine{
+			{ProjectID: "mockID", Type: "build", Log: "docker.build", Time: "2020-02-01T10:05:00Z", Line: "Step 2/3"},
+		}, lines)
+	})
+
+	t.Run("fail case: project not found", func(t *testing.T) {
+		body := ioutil.NopCloser(bytes.NewReader([]byte("")))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusNotFound, Body: body}
+		projErr := streamPFELogs(mockClient, &mockConnection, "dummyurl", "mockID", LogOptions{Type: "build", Tail: "all"}, func(line LogLine) {})
+		assert.Equal(t, errOpNotFound, projErr.Op)
+	})
+}
+
+func TestDialPFESocketThroughProxy(t *testing.T) {
+	server := mockPFELogs(t, []string{`42/default,["log-update", {"projectID": "mockID", "logType": "build", "logName": "docker.build", "logs": "Step 1/3\n"}]`})
+	defer server.Close()
+	// a proxy that tunnels every CONNECT request to the mock Codewind server, as localhost is never proxied
+	tunnels := make(chan string, 1)
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tunnels <- r.Host
+		serverConn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		clientConn, _, _ := w.(http.Hijacker).Hijack()
+		clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go io.Copy(serverConn, clientConn)
+		io.Copy(clientConn, serverConn)
+		clientConn.Close()
+	}))
+	defer proxyServer.Close()
+
+	mockConnection := connections.Connection{ID: "remote", HTTPProxy: proxyServer.URL}
+	socket, err := dialPFESocket(&mockConnection, "http://codewind.example.com", "/default", "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer socket.Close()
+	assert.Equal(t, "codewind.example.com:80", <-tunnels)
+
+	// the socket works once open, as the mock server only sends events once streaming is turned on
+	resp, err := http.Post(server.URL+"/api/v1/projects/mockID/logs", "application/json", nil)
+	if assert.Nil(t, err) {
+		resp.Body.Close()
+	}
+	event, _, err := socket.readEvent(time.Now().Add(5 * time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, "log-update", event)
+}
+
+func TestLogSinceTime(t *testing.T) {
+	now := time.Date(2020, 2, 1, 10, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		since string
+		want  time.Time
+	}{
+		"no since":  {"", time.Time{}},
+		"timestamp": {"2020-02-01T09:00:00Z", time.Date(2020, 2, 1, 9, 0, 0, 0, time.UTC)},
+		"duration":  {"10m", now.Add(-10 * time.Minute)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := logSinceTime(test.since, now)
+			assert.Nil(t, err)
+			assert.True(t, test.want.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		tail    string
+		want    string
+	}{
+		"all lines":                       {"a\nb\nc\n", "all", "a\nb\nc\n"},
+		"last lines":                      {"a\nb\nc\n", "2", "b\nc\n"},
+		"last lines without a last break": {"a\nb\nc", "1", "c"},
+		"more lines than the log has":     {"a\nb\n", "5", "a\nb\n"},
+		"no lines":                        {"a\nb\n", "0", ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, tailLines(test.content, test.tail))
+		})
+	}
+}
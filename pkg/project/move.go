@@ -0,0 +1,157 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// MoveResponse represents the result of moving a project between connections
+type MoveResponse struct {
+	SourceConnectionID string        `json:"sourceConnectionID"`
+	TargetConnectionID string        `json:"targetConnectionID"`
+	OldProjectID       string        `json:"oldProjectID"`
+	Bind               *BindResponse `json:"bind"`
+	RestoredLinks      []Link        `json:"restoredLinks"`
+	SkippedLinks       []SkippedLink `json:"skippedLinks"`
+}
+
+// MoveProject unbinds a project from its current connection and binds it to the target connection,
+// recreating its links where the target projects exist. If the project cannot be bound to the target
+// connection it is bound back to its original connection, with its links and the links to it restored.
+func MoveProject(httpClient utils.HTTPClient, projectID, targetConID string) (*MoveResponse, *ProjectError) {
+	sourceConID, projErr := GetConnectionID(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if sourceConID == targetConID {
+		err := errors.New(textProjectAlreadyBound + " " + targetConID)
+		return nil, &ProjectError{errOpMove, err, err.Error()}
+	}
+
+	sourceConInfo, sourceConURL, projErr := getConnectionAndURL(sourceConID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	// Check the target connection before touching the project on the source connection
+	targetConInfo, targetConURL, projErr := getConnectionAndURL(targetConID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	project, projErr := GetProjectFromID(httpClient, sourceConInfo, sourceConURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	links, projErr := GetProjectLinks(httpClient, sourceConInfo, sourceConURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	incomingLinks, projErr := getLinksToProject(httpClient, sourceConInfo, sourceConURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	projErr = Unbind(httpClient, sourceConInfo, sourceConURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	// We can ignore errors as the file is no longer created
+	RemoveConnectionFile(projectID)
+
+	bindResponse, bindErr := Bind(project.LocationOnDisk, project.Name, project.Language, project.ProjectType, targetConID)
+	if bindErr != nil {
+		newProjectID, rollbackErr := rollbackMove(httpClient, sourceConInfo, sourceConURL, project, links, incomingLinks)
+		return nil, moveFailedError(targetConID, sourceConID, newProjectID, bindErr, rollbackErr)
+	}
+
+	restored, skipped := restoreLinks(httpClient, targetConInfo, targetConURL, bindResponse.ProjectID, links)
+	return &MoveResponse{
+		SourceConnectionID: sourceConID,
+		TargetConnectionID: targetConID,
+		OldProjectID:       projectID,
+		Bind:               bindResponse,
+		RestoredLinks:      restored,
+		SkippedLinks:       skipped,
+	}, nil
+}
+
+// getLinksToProject returns the links of the other projects on a connection that point at a project
+func getLinksToProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string) ([]GraphLink, *ProjectError) {
+	graph, projErr := BuildLinkGraph(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+	incomingLinks := []GraphLink{}
+	for _, link := range graph.Links {
+		if link.TargetProjectID == projectID && link.SourceProjectID != projectID {
+			incomingLinks = append(incomingLinks, link)
+		}
+	}
+	return incomingLinks, nil
+}
+
+// rollbackMove binds a project back to the connection it was moved from, restores its links and points the
+// links of other projects that pointed at it at its new ID. The new ID is returned if it was bound back,
+// even when some links could not be restored.
+func rollbackMove(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, project *Project, links []Link, incomingLinks []GraphLink) (string, *ProjectError) {
+	bindResponse, projErr := Bind(project.LocationOnDisk, project.Name, project.Language, project.ProjectType, conInfo.ID)
+	if projErr != nil {
+		return "", projErr
+	}
+	newProjectID := bindResponse.ProjectID
+	_, skipped := restoreLinks(httpClient, conInfo, conURL, newProjectID, links)
+	failed := len(skipped)
+	for _, link := range incomingLinks {
+		// the link to the old ID is left behind by the unbind, so it has to be removed to reuse its name
+		DeleteProjectLink(httpClient, conInfo, conURL, link.SourceProjectID, link.EnvName)
+		if CreateProjectLink(httpClient, conInfo, conURL, link.SourceProjectID, newProjectID, link.EnvName) != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		err := fmt.Errorf("%d of %d project links could not be restored", failed, len(links)+len(incomingLinks))
+		return newProjectID, &ProjectError{errOpMove, err, err.Error()}
+	}
+	return newProjectID, nil
+}
+
+// moveFailedError combines the reason a move failed with the outcome of rolling it back, including the
+// new ID of the project when it was bound back
+func moveFailedError(targetConID, sourceConID, newProjectID string, bindErr, rollbackErr *ProjectError) *ProjectError {
+	rollbackOutcome := "the project was bound back to connection " + sourceConID + " with ID " + newProjectID
+	if rollbackErr != nil && newProjectID == "" {
+		rollbackOutcome = "binding the project back to connection " + sourceConID + " also failed: " + rollbackErr.Desc
+	} else if rollbackErr != nil {
+		rollbackOutcome += ", but " + rollbackErr.Desc
+	}
+	err := fmt.Errorf("failed to bind project to connection %s: %s; %s", targetConID, bindErr.Desc, rollbackOutcome)
+	return &ProjectError{errOpMove, err, err.Error()}
+}
+
+// getConnectionAndURL returns the connection with the given ID and its PFE URL
+func getConnectionAndURL(conID string) (*connections.Connection, string, *ProjectError) {
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		return nil, "", &ProjectError{errOpConNotFound, conInfoErr.Err, conInfoErr.Desc}
+	}
+	conURL, conURLErr := config.PFEOriginFromConnection(conInfo)
+	if conURLErr != nil {
+		return nil, "", &ProjectError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
+	}
+	return conInfo, conURL, nil
+}
@@ -0,0 +1,44 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveFailedError(t *testing.T) {
+	bindErr := &ProjectError{errOpResponse, errors.New(textDupName), textDupName}
+
+	t.Run("rollback succeeded", func(t *testing.T) {
+		got := moveFailedError("remote", "local", "new-id", bindErr, nil)
+		assert.Equal(t, errOpMove, got.Op)
+		assert.Equal(t, "failed to bind project to connection remote: "+textDupName+"; the project was bound back to connection local with ID new-id", got.Desc)
+	})
+
+	t.Run("rollback restored only some links", func(t *testing.T) {
+		linksErr := &ProjectError{errOpMove, errors.New("1 of 2 project links could not be restored"), "1 of 2 project links could not be restored"}
+		got := moveFailedError("remote", "local", "new-id", bindErr, linksErr)
+		assert.Equal(t, errOpMove, got.Op)
+		assert.Contains(t, got.Desc, "the project was bound back to connection local with ID new-id, but 1 of 2 project links could not be restored")
+	})
+
+	t.Run("rollback failed", func(t *testing.T) {
+		rollbackErr := &ProjectError{errOpConNotFound, errors.New(textNoCodewind), textNoCodewind}
+		got := moveFailedError("remote", "local", "", bindErr, rollbackErr)
+		assert.Equal(t, errOpMove, got.Op)
+		assert.Contains(t, got.Desc, textDupName)
+		assert.Contains(t, got.Desc, "binding the project back to connection local also failed: "+textNoCodewind)
+	})
+}
@@ -22,8 +22,20 @@ import (
 	"github.com/eclipse/codewind-installer/pkg/connections"
 )
 
-// GetConnectionID : Gets the the connectionID for a given projectID
+// GetConnectionID : Gets the the connectionID for a given projectID. The connection in the project index is
+// used if it has the project, and returned with the error if it cannot be reached, so a connection that is down
+// does not make every lookup ask every other connection. Otherwise every connection is asked and the index is
+// corrected with the answer.
 func GetConnectionID(projectID string) (string, *ProjectError) {
+	if conID, ok := lookupProjectIndex(projectID); ok {
+		projErr := checkProjectOnConnection(projectID, conID)
+		if projErr == nil || projErr.Op != errOpNotFound {
+			return conID, projErr
+		}
+		// The project is no longer on the connection, such as after it was moved by another client
+		RemoveProjectFromIndex(projectID)
+	}
+
 	allConnections, getConConfigErr := connections.GetConnectionsConfig()
 	if getConConfigErr != nil {
 		return "", &ProjectError{errOpConNotFound, getConConfigErr, getConConfigErr.Error()}
@@ -51,6 +63,8 @@ func GetConnectionID(projectID string) (string, *ProjectError) {
 
 		for _, project := range projects {
 			if project.ProjectID == projectID {
+				// We can ignore errors as the index is only used to speed up lookups
+				AddProjectToIndex(projectID, currentConID, project.Name)
 				return currentConID, nil
 			}
 		}
@@ -60,6 +74,16 @@ func GetConnectionID(projectID string) (string, *ProjectError) {
 	return "", &ProjectError{errOpConNotFound, projError, projError.Error()}
 }
 
+// checkProjectOnConnection asks a connection whether it has a project
+func checkProjectOnConnection(projectID, conID string) *ProjectError {
+	conInfo, conURL, projErr := getConnectionAndURL(conID)
+	if projErr != nil {
+		return projErr
+	}
+	_, projErr = GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
+	return projErr
+}
+
 // RemoveConnectionFile : Remove the connection file for a project
 func RemoveConnectionFile(projectID string) *ProjectError {
 	// delete file
@@ -0,0 +1,194 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ProjectIndex : Records which connection each bound project is on, so it can be found without asking every connection
+	ProjectIndex struct {
+		Projects map[string]IndexEntry `json:"projects"`
+	}
+
+	// IndexEntry : The connection a project is bound to
+	IndexEntry struct {
+		ConnectionID string `json:"connectionID"`
+		Name         string `json:"name,omitempty"`
+	}
+
+	// ReindexResult : The outcome of rebuilding the project index
+	ReindexResult struct {
+		Connections []ReindexedConnection `json:"connections"`
+		Projects    int                   `json:"projects"`
+	}
+
+	// ReindexedConnection : The outcome of rebuilding the project index for one connection
+	ReindexedConnection struct {
+		ConnectionID string `json:"connectionID"`
+		Projects     int    `json:"projects"`
+		Error        string `json:"error,omitempty"`
+	}
+)
+
+const projectIndexFilename = "projects-index.json"
+
+// AddProjectToIndex records the connection a project is bound to
+func AddProjectToIndex(projectID, conID, name string) *ProjectError {
+	return updateProjectIndex(getProjectIndexFilename(), func(index *ProjectIndex) {
+		index.Projects[projectID] = IndexEntry{ConnectionID: conID, Name: name}
+	})
+}
+
+// RemoveProjectFromIndex forgets the connection of a project that has been unbound
+func RemoveProjectFromIndex(projectID string) *ProjectError {
+	return updateProjectIndex(getProjectIndexFilename(), func(index *ProjectIndex) {
+		delete(index.Projects, projectID)
+	})
+}
+
+// ReindexProjects rebuilds the project index from the projects on every connection. The entries of
+// connections that cannot be reached are kept, as their projects are still bound to them.
+func ReindexProjects(httpClient utils.HTTPClient) (*ReindexResult, *ProjectError) {
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		return nil, &ProjectError{errOpConNotFound, conErr.Err, conErr.Desc}
+	}
+
+	result := &ReindexResult{Connections: []ReindexedConnection{}}
+	found := map[string][]Project{}
+	knownConnections := map[string]bool{}
+	for _, connection := range allConnections {
+		knownConnections[connection.ID] = true
+		reindexed := ReindexedConnection{ConnectionID: connection.ID}
+		projects, projErr := getConnectionProjects(httpClient, connection.ID)
+		if projErr != nil {
+			reindexed.Error = projErr.Desc
+		} else {
+			found[connection.ID] = projects
+			reindexed.Projects = len(projects)
+		}
+		result.Connections = append(result.Connections, reindexed)
+	}
+
+	projErr := updateProjectIndex(getProjectIndexFilename(), func(index *ProjectIndex) {
+		rebuildProjectIndex(index, found, knownConnections)
+		result.Projects = len(index.Projects)
+	})
+	if projErr != nil {
+		return nil, projErr
+	}
+	return result, nil
+}
+
+// rebuildProjectIndex replaces the entries of each connection that was reached with the projects found on it,
+// and drops entries for connections that no longer exist
+func rebuildProjectIndex(index *ProjectIndex, found map[string][]Project, knownConnections map[string]bool) {
+	for projectID, entry := range index.Projects {
+		_, reached := found[entry.ConnectionID]
+		if reached || !knownConnections[entry.ConnectionID] {
+			delete(index.Projects, projectID)
+		}
+	}
+	conIDs := []string{}
+	for conID := range found {
+		conIDs = append(conIDs, conID)
+	}
+	sort.Strings(conIDs)
+	for _, conID := range conIDs {
+		for _, project := range found[conID] {
+			index.Projects[project.ProjectID] = IndexEntry{ConnectionID: conID, Name: project.Name}
+		}
+	}
+}
+
+// lookupProjectIndex returns the connection recorded for a project, if the connection still exists.
+// The index is replaced by renaming a complete file over it, so it is read without taking its lock.
+func lookupProjectIndex(projectID string) (string, bool) {
+	index := loadProjectIndex(getProjectIndexFilename())
+	entry, ok := index.Projects[projectID]
+	if !ok {
+		return "", false
+	}
+	if _, conErr := connections.GetConnectionByID(entry.ConnectionID); conErr != nil {
+		// The connection has been removed, so the entry can no longer be trusted
+		RemoveProjectFromIndex(projectID)
+		return "", false
+	}
+	return entry.ConnectionID, true
+}
+
+// getConnectionProjects returns the projects on a connection
+func getConnectionProjects(httpClient utils.HTTPClient, conID string) ([]Project, *ProjectError) {
+	conInfo, conURL, projErr := getConnectionAndURL(conID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	return GetAll(httpClient, conInfo, conURL)
+}
+
+// updateProjectIndex holds the project index lock, shared with every cwctl process, while it loads
+// the index, applies the update and saves it
+func updateProjectIndex(indexPath string, update func(*ProjectIndex)) *ProjectError {
+	unlock, conErr := connections.LockFile(indexPath)
+	if conErr != nil {
+		return &ProjectError{errOpFileWrite, conErr.Err, conErr.Desc}
+	}
+	defer unlock()
+	index := loadProjectIndex(indexPath)
+	update(index)
+	return saveProjectIndex(indexPath, index)
+}
+
+// loadProjectIndex reads the project index. A missing or unreadable index is treated as empty,
+// as it is rebuilt as projects are looked up.
+func loadProjectIndex(indexPath string) *ProjectIndex {
+	index := &ProjectIndex{}
+	data, err := ioutil.ReadFile(indexPath)
+	if err == nil {
+		err = json.Unmarshal(data, index)
+	}
+	if err != nil || index.Projects == nil {
+		index.Projects = map[string]IndexEntry{}
+	}
+	return index
+}
+
+// saveProjectIndex writes the project index the same crash-safe way as the connections config, so a
+// reader never sees a partly written index
+func saveProjectIndex(indexPath string, index *ProjectIndex) *ProjectError {
+	err := os.MkdirAll(path.Dir(indexPath), 0755)
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err == nil {
+		err = utils.WriteFileAtomically(indexPath, data)
+	}
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// getProjectIndexFilename : Get full file path of the project index
+func getProjectIndexFilename() string {
+	return path.Join(getProjectConnectionConfigDir(), projectIndexFilename)
+}
@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectIndexFile(t *testing.T) {
+	os.RemoveAll("testDir")
+	defer os.RemoveAll("testDir")
+	indexPath := filepath.Join("testDir", "config", projectIndexFilename)
+
+	t.Run("success case: a missing index is empty", func(t *testing.T) {
+		index := loadProjectIndex(indexPath)
+		assert.Empty(t, index.Projects)
+	})
+
+	t.Run("success case: entries are added and removed", func(t *testing.T) {
+		projErr := updateProjectIndex(indexPath, func(index *ProjectIndex) {
+			index.Projects["1"] = IndexEntry{ConnectionID: "local", Name: "api"}
+			index.Projects["2"] = IndexEntry{ConnectionID: "remote", Name: "web"}
+		})
+		assert.Nil(t, projErr)
+		projErr = updateProjectIndex(indexPath, func(index *ProjectIndex) {
+			delete(index.Projects, "1")
+		})
+		assert.Nil(t, projErr)
+		assert.Equal(t, map[string]IndexEntry{"2": {ConnectionID: "remote", Name: "web"}}, loadProjectIndex(indexPath).Projects)
+		files, _ := ioutil.ReadDir(filepath.Dir(indexPath))
+		for _, file := range files {
+			assert.NotContains(t, file.Name(), ".tmp")
+		}
+	})
+
+	t.Run("success case: concurrent updates are all kept", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				projErr := updateProjectIndex(indexPath, func(index *ProjectIndex) {
+					index.Projects["concurrent"+strconv.Itoa(i)] = IndexEntry{ConnectionID: "local"}
+				})
+				assert.Nil(t, projErr)
+			}(i)
+		}
+		wg.Wait()
+		assert.Len(t, loadProjectIndex(indexPath).Projects, 11)
+		_, err := os.Stat(indexPath + ".lock")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("success case: a corrupt index is treated as empty", func(t *testing.T) {
+		ioutil.WriteFile(indexPath, []byte("{not json"), 0644)
+		assert.Empty(t, loadProjectIndex(indexPath).Projects)
+	})
+}
+
+func TestRebuildProjectIndex(t *testing.T) {
+	index := &ProjectIndex{Projects: map[string]IndexEntry{
+		"stale":   {ConnectionID: "local", Name: "removed"},
+		"offline": {ConnectionID: "remote", Name: "kept"},
+		"orphan":  {ConnectionID: "deleted", Name: "orphan"},
+	}}
+	found := map[string][]Project{
+		"local": {{ProjectID: "1", Name: "api"}, {ProjectID: "2", Name: "web"}},
+	}
+	known := map[string]bool{"local": true, "remote": true}
+
+	rebuildProjectIndex(index, found, known)
+	assert.Equal(t, map[string]IndexEntry{
+		"1":       {ConnectionID: "local", Name: "api"},
+		"2":       {ConnectionID: "local", Name: "web"},
+		"offline": {ConnectionID: "remote", Name: "kept"},
+	}, index.Projects)
+}
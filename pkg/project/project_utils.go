@@ -26,29 +26,56 @@ type (
 )
 
 const (
-	errBadPath           = "proj_path" // Invalid path provided
-	errBadType           = "proj_type" // Invalid type provided
-	errOpBind            = "proj_bind"
-	errOpRequest         = "proj_request"
-	errOpResponse        = "proj_response" // Bad response to http
-	errOpFileParse       = "proj_parse"
-	errOpFileLoad        = "proj_load"
-	errOpFileWrite       = "proj_write"
-	errOpFileDelete      = "proj_delete"
-	errOpUnbind          = "proj_unbind"
-	errOpGetProject      = "proj_get"
-	errOpCreateProject   = "project create"
-	errOpConflict        = "proj_conflict"
-	errOpNotFound        = "proj_notfound"
-	errOpConNotFound     = "connection_notfound"
-	errOpInvalidID       = "proj_id_invalid"
-	errOpInvalidOptions  = "proj_options_invalid"
-	errOpSync            = "proj_sync"
-	errOpSyncRef         = "proj_sync_ref"
-	errOpWriteCwSettings = "proj_write_cw_settings"
+	errBadPath              = "proj_path" // Invalid path provided
+	errBadType              = "proj_type" // Invalid type provided
+	errOpBind               = "proj_bind"
+	errOpRequest            = "proj_request"
+	errOpResponse           = "proj_response" // Bad response to http
+	errOpFileParse          = "proj_parse"
+	errOpFileLoad           = "proj_load"
+	errOpFileWrite          = "proj_write"
+	errOpFileDelete         = "proj_delete"
+	errOpUnbind             = "proj_unbind"
+	errOpGetProject         = "proj_get"
+	errOpCreateProject      = "project create"
+	errOpConflict           = "proj_conflict"
+	errOpNotFound           = "proj_notfound"
+	errOpConNotFound        = "connection_notfound"
+	errOpInvalidID          = "proj_id_invalid"
+	errOpSync               = "proj_sync"
+	errOpSyncRef            = "proj_sync_ref"
+	errOpWriteCwSettings    = "proj_write_cw_settings"
 	errOpInvalidCredentials = "invalid_git_credentials"
+	errOpTemplateNotFound   = "proj_template_notfound"
+	errOpTemplateAmbiguous  = "proj_template_ambiguous"
+	errOpInvalidSettings    = "proj_settings_invalid"
+	errOpExport             = "proj_export"
+	errOpImport             = "proj_import"
+	errOpMove               = "proj_move"
+	errOpLogs               = "proj_logs"
+	errOpNameAmbiguous      = "proj_name_ambiguous"
+	errOpDebug              = "proj_debug"
 )
 
+// Ops of the project errors that callers outside this package act on, such as project wait, which exits
+// with its own status for each of them so scripts can tell why it stopped waiting
+const (
+	ErrOpInvalidOptions = "proj_options_invalid"
+	ErrOpWaitTimeout    = "proj_wait_timeout"
+	ErrOpBuildFailed    = "proj_build_failed"
+)
+
+// ExitCode : The exit status for a project error, 1 unless its op has a status of its own
+func ExitCode(projErr *ProjectError) int {
+	switch projErr.Op {
+	case ErrOpWaitTimeout:
+		return 2
+	case ErrOpBuildFailed:
+		return 3
+	}
+	return 1
+}
+
 const (
 	textDupName                   = "project name is already in use"
 	textInvalidType               = "project type is invalid"
@@ -66,6 +93,38 @@ const (
 	textProjectLinkTargetNotFound = "target project not found on Codewind server"
 	textProjectLinkConflict       = "project link env is already in use"
 	textInvalidRequest            = "request parameters are invalid"
+	textNoTemplateName            = "template name not given"
+	textTemplateNotFound          = "no template found matching"
+	textTemplateAmbiguous         = "more than one template matches"
+	textValidationFailed          = "project validation failed, unable to bind project"
+	textSettingsNotObject         = ".cw-settings must contain a JSON object"
+	textSettingNotSet             = "setting not found in .cw-settings"
+	textUnknownSetting            = "unknown setting"
+	textBundleNoManifest          = "bundle does not contain a project manifest"
+	textBundleVersion             = "bundle was created by a newer version of cwctl"
+	textBundleInvalidPath         = "bundle contains a file outside of the project"
+	textProjectAlreadyBound       = "project is already bound to connection"
+	textUpgradeMissingInfo        = "Unable to upgrade project, failed to determine project details"
+	textNoProjectContainer        = "project does not have a container, it may not have been built yet"
+	textInvalidLogType            = "log type must be app or build"
+	textInvalidLogTail            = "tail must be a number of lines or all"
+	textInvalidLogSince           = "since must be a timestamp or a duration"
+	textSocketNotOpened           = "Codewind server did not open a socket session"
+	textSocketProxyRefused        = "proxy refused to connect to the Codewind server socket"
+	textInvalidWaitState          = "state to wait for must be started, stopped or built"
+	textInvalidWaitTimeout        = "timeout must be greater than zero"
+	textWaitTimeout               = "timed out waiting for project to be"
+	textBuildFailed               = "project build failed"
+	textNoProjectSelector         = "specify a project ID or name, or select projects with all, name-pattern, language or status"
+	textSelectorWithID            = "a project ID or name cannot be combined with all, name-pattern, language or status"
+	textInvalidNamePattern        = "name pattern is invalid"
+	textIDAndName                 = "specify either a project ID or a project name, not both"
+	textNoIDOrName                = "must specify either a project ID or a project name"
+	textNameAmbiguous             = "more than one connection has a project named"
+	textNameNotFound              = "no project found named"
+	textNoDebugPort               = "project does not have a debug port, its project type may not support debugging"
+	textDebugPortNotPublished     = "project debug port is not published by its container"
+	textInvalidFilter             = "filter must be of the form field=pattern or field!=pattern"
 )
 
 // ProjectError : Error formatted in JSON containing an errorOp and a description from
@@ -18,6 +18,7 @@ import (
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
 )
 
@@ -25,6 +26,11 @@ import (
 func RemoveProject(c *cli.Context) *ProjectError {
 	projectID := strings.TrimSpace(c.String("id"))
 	deleteFiles := c.Bool("delete")
+	return RemoveProjectByID(http.DefaultClient, projectID, deleteFiles)
+}
+
+// RemoveProjectByID : Unbind a project from Codewind, delete its json connection file and, optionally, its source
+func RemoveProjectByID(httpClient utils.HTTPClient, projectID string, deleteFiles bool) *ProjectError {
 	projectPath := ""
 
 	// Get the connection for this project
@@ -45,7 +51,7 @@ func RemoveProject(c *cli.Context) *ProjectError {
 
 	// If we are deleting the source, retrieve project to find out the path
 	if deleteFiles {
-		project, projErr := GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
 		if projErr != nil {
 			return projErr
 		}
@@ -53,7 +59,7 @@ func RemoveProject(c *cli.Context) *ProjectError {
 	}
 
 	// Unbind the project from codewind
-	projError := Unbind(http.DefaultClient, conInfo, conURL, projectID)
+	projError := Unbind(httpClient, conInfo, conURL, projectID)
 	if projError != nil {
 		return projError
 	}
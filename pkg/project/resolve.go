@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// connectionProjects holds the projects found on one connection
+type connectionProjects struct {
+	conID    string
+	projects []Project
+}
+
+// ResolveProjectID returns the ID of a project given either its ID or its name. A name is looked up on the
+// given connection or, if conID is empty, on every connection. The connection the project was found on is
+// returned for a name, and is empty for an ID.
+func ResolveProjectID(httpClient utils.HTTPClient, projectID, projectName, conID string) (string, string, *ProjectError) {
+	if projectID != "" && projectName != "" {
+		err := errors.New(textIDAndName)
+		return "", "", &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if projectID != "" {
+		return projectID, "", nil
+	}
+	if projectName == "" {
+		err := errors.New(textNoIDOrName)
+		return "", "", &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+
+	conIDs := []string{conID}
+	if conID == "" {
+		conIDs = []string{}
+		allConnections, conErr := connections.GetAllConnections()
+		if conErr != nil {
+			return "", "", &ProjectError{errOpConNotFound, conErr.Err, conErr.Desc}
+		}
+		for _, connection := range allConnections {
+			conIDs = append(conIDs, connection.ID)
+		}
+	}
+
+	found := []connectionProjects{}
+	unreachable := []string{}
+	for _, id := range conIDs {
+		conInfo, conURL, projErr := getConnectionAndURL(id)
+		if projErr != nil {
+			if conID != "" {
+				return "", "", projErr
+			}
+			unreachable = append(unreachable, id)
+			continue
+		}
+		projects, projErr := GetAll(httpClient, conInfo, conURL)
+		if projErr != nil {
+			if conID != "" {
+				return "", "", projErr
+			}
+			unreachable = append(unreachable, id)
+			continue
+		}
+		found = append(found, connectionProjects{id, projects})
+	}
+	return findNamedProject(projectName, found, unreachable)
+}
+
+// findNamedProject returns the ID and connection of the only project with the given name
+func findNamedProject(projectName string, found []connectionProjects, unreachable []string) (string, string, *ProjectError) {
+	projectID, projectConID := "", ""
+	matchedConIDs := []string{}
+	for _, connection := range found {
+		for _, project := range connection.projects {
+			if project.Name == projectName {
+				projectID, projectConID = project.ProjectID, connection.conID
+				matchedConIDs = append(matchedConIDs, connection.conID)
+			}
+		}
+	}
+
+	switch {
+	case len(matchedConIDs) > 1:
+		err := errors.New(textNameAmbiguous + " " + projectName + ": " + strings.Join(matchedConIDs, ", ") + ", use --conid or --id")
+		return "", "", &ProjectError{errOpNameAmbiguous, err, err.Error()}
+	case len(matchedConIDs) == 0:
+		desc := textNameNotFound + " " + projectName
+		if len(unreachable) > 0 {
+			desc += ", unable to reach connections: " + strings.Join(unreachable, ", ")
+		}
+		err := errors.New(desc)
+		return "", "", &ProjectError{errOpNotFound, err, err.Error()}
+	}
+	return projectID, projectConID, nil
+}
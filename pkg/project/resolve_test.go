@@ -0,0 +1,64 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveProjectID(t *testing.T) {
+	t.Run("success case: an ID is returned as it is", func(t *testing.T) {
+		projectID, conID, projErr := ResolveProjectID(http.DefaultClient, "mockID", "", "")
+		assert.Nil(t, projErr)
+		assert.Equal(t, "mockID", projectID)
+		assert.Equal(t, "", conID)
+	})
+
+	t.Run("fail case: both an ID and a name", func(t *testing.T) {
+		_, _, projErr := ResolveProjectID(http.DefaultClient, "mockID", "mockName", "")
+		assert.Equal(t, ErrOpInvalidOptions, projErr.Op)
+	})
+
+	t.Run("fail case: neither an ID nor a name", func(t *testing.T) {
+		_, _, projErr := ResolveProjectID(http.DefaultClient, "", "", "")
+		assert.Equal(t, ErrOpInvalidOptions, projErr.Op)
+	})
+}
+
+func TestFindNamedProject(t *testing.T) {
+	found := []connectionProjects{
+		{"local", []Project{{ProjectID: "1", Name: "api"}, {ProjectID: "2", Name: "web"}}},
+		{"remote", []Project{{ProjectID: "3", Name: "web"}, {ProjectID: "4", Name: "db"}}},
+	}
+
+	t.Run("success case: the project is found on its connection", func(t *testing.T) {
+		projectID, conID, projErr := findNamedProject("db", found, nil)
+		assert.Nil(t, projErr)
+		assert.Equal(t, "4", projectID)
+		assert.Equal(t, "remote", conID)
+	})
+
+	t.Run("fail case: two connections have a project with the name", func(t *testing.T) {
+		_, _, projErr := findNamedProject("web", found, nil)
+		assert.Equal(t, errOpNameAmbiguous, projErr.Op)
+		assert.Equal(t, "more than one connection has a project named web: local, remote, use --conid or --id", projErr.Desc)
+	})
+
+	t.Run("fail case: no project has the name", func(t *testing.T) {
+		_, _, projErr := findNamedProject("cache", found, []string{"offline"})
+		assert.Equal(t, errOpNotFound, projErr.Op)
+		assert.Equal(t, "no project found named cache, unable to reach connections: offline", projErr.Desc)
+	})
+}
@@ -0,0 +1,429 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type (
+	// SettingsFile is a .cw-settings file which can be edited without losing
+	// unknown fields, the order of the fields or the formatting of untouched values
+	SettingsFile struct {
+		Path            string
+		indent          string
+		trailingNewline bool
+		keys            []string
+		values          map[string]json.RawMessage
+	}
+
+	// SettingsIssue describes a problem found when validating a .cw-settings file
+	SettingsIssue struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	}
+
+	// SettingsValidationResponse represents the result of validating a .cw-settings file
+	SettingsValidationResponse struct {
+		Status      string          `json:"status"`
+		Path        string          `json:"projectPath"`
+		ProjectType string          `json:"projectType"`
+		Issues      []SettingsIssue `json:"issues"`
+	}
+
+	// settingKind is the type of value a setting holds
+	settingKind int
+
+	// settingSchema describes a known .cw-settings field
+	settingSchema struct {
+		kind         settingKind
+		projectTypes []string // the project types the setting applies to, or all if empty
+	}
+)
+
+const (
+	settingString settingKind = iota
+	settingPath
+	settingPort
+	settingNumber
+	settingBool
+	settingStringList
+)
+
+const cwSettingsFilename = ".cw-settings"
+
+// cwSettingsSchema holds the fields of CWSettings that PFE understands
+var cwSettingsSchema = map[string]settingSchema{
+	"contextRoot":       {kind: settingString},
+	"internalPort":      {kind: settingPort},
+	"healthCheck":       {kind: settingPath},
+	"internalDebugPort": {kind: settingPort, projectTypes: projectTypesWithInternalDebugPort},
+	"isHttps":           {kind: settingBool},
+	"ignoredPaths":      {kind: settingStringList},
+	"mavenProfiles":     {kind: settingStringList, projectTypes: projectTypesWithMavenSettings},
+	"mavenProperties":   {kind: settingStringList, projectTypes: projectTypesWithMavenSettings},
+	"statusPingTimeout": {kind: settingNumber},
+}
+
+// LoadSettingsFile reads the .cw-settings file of the project at the given path
+func LoadSettingsFile(projectPath string) (*SettingsFile, *ProjectError) {
+	projErr := checkProjectPathExists(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	settingsPath := filepath.Join(projectPath, cwSettingsFilename)
+	contents, err := ioutil.ReadFile(settingsPath)
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	settings, err := parseSettingsFile(settingsPath, contents)
+	if err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return settings, nil
+}
+
+func parseSettingsFile(settingsPath string, contents []byte) (*SettingsFile, error) {
+	settings := SettingsFile{
+		Path:            settingsPath,
+		indent:          detectIndent(contents),
+		trailingNewline: bytes.HasSuffix(contents, []byte("\n")),
+		values:          make(map[string]json.RawMessage),
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New(textSettingsNotObject)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := token.(string)
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := settings.values[key]; !exists {
+			settings.keys = append(settings.keys, key)
+		}
+		settings.values[key] = value
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// detectIndent returns the indent used by the first indented line of the file,
+// defaulting to the two spaces used when writing a new .cw-settings file
+func detectIndent(contents []byte) string {
+	for _, line := range strings.Split(string(contents), "\n")[1:] {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
+// Keys returns the fields in the file in the order they appear
+func (settings *SettingsFile) Keys() []string {
+	return append([]string{}, settings.keys...)
+}
+
+// Get returns the raw JSON value of a field, and whether it is set
+func (settings *SettingsFile) Get(key string) (json.RawMessage, bool) {
+	value, ok := settings.values[key]
+	return value, ok
+}
+
+// Set updates a field, converting the given string into the type the schema expects for it.
+// Fields that are not already in the file are added to the end
+func (settings *SettingsFile) Set(key, value string) *ProjectError {
+	rawValue, err := settingValueFromString(key, value)
+	if err != nil {
+		return &ProjectError{errOpInvalidSettings, err, err.Error()}
+	}
+	// indent new values to match the rest of the file
+	var indented bytes.Buffer
+	if json.Indent(&indented, rawValue, settings.indent, settings.indent) == nil {
+		rawValue = indented.Bytes()
+	}
+	if _, exists := settings.values[key]; !exists {
+		settings.keys = append(settings.keys, key)
+	}
+	settings.values[key] = rawValue
+	return nil
+}
+
+// Unset removes a field from the file
+func (settings *SettingsFile) Unset(key string) *ProjectError {
+	if _, exists := settings.values[key]; !exists {
+		err := fmt.Errorf("%s: %s", textSettingNotSet, key)
+		return &ProjectError{errOpNotFound, err, err.Error()}
+	}
+	delete(settings.values, key)
+	for i, existingKey := range settings.keys {
+		if existingKey == key {
+			settings.keys = append(settings.keys[:i], settings.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Validate checks every field in the file against the schema for the given project type
+func (settings *SettingsFile) Validate(projectType string) []SettingsIssue {
+	issues := []SettingsIssue{}
+	for _, key := range settings.keys {
+		issues = append(issues, validateSetting(key, settings.values[key], projectType)...)
+	}
+	return issues
+}
+
+// Save writes the file back to disk, leaving untouched values as they were
+func (settings *SettingsFile) Save() *ProjectError {
+	// File permission 0644 grants read and write access to the owner
+	err := ioutil.WriteFile(settings.Path, settings.Bytes(), 0644)
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// Bytes returns the contents of the file as they will be written to disk
+func (settings *SettingsFile) Bytes() []byte {
+	var buf bytes.Buffer
+	if len(settings.keys) == 0 {
+		buf.WriteString("{}")
+	} else {
+		buf.WriteString("{\n")
+		for i, key := range settings.keys {
+			jsonKey, _ := json.Marshal(key)
+			buf.WriteString(settings.indent)
+			buf.Write(jsonKey)
+			buf.WriteString(": ")
+			buf.Write(settings.values[key])
+			if i < len(settings.keys)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}")
+	}
+	if settings.trailingNewline {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// ValidateSettings validates the .cw-settings file of the project at the given path.
+// If no project type is given, it is detected from the project files
+func ValidateSettings(projectPath, projectType string) (*SettingsValidationResponse, *ProjectError) {
+	settings, projErr := LoadSettingsFile(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if projectType == "" {
+		_, projectType = determineProjectInfo(projectPath)
+	}
+	issues := settings.Validate(projectType)
+	status := "success"
+	if len(issues) > 0 {
+		status = "failed"
+	}
+	return &SettingsValidationResponse{
+		Status:      status,
+		Path:        projectPath,
+		ProjectType: projectType,
+		Issues:      issues,
+	}, nil
+}
+
+// GetSetting returns the raw JSON value of a field in the .cw-settings file of the project at the given path
+func GetSetting(projectPath, key string) (json.RawMessage, *ProjectError) {
+	settings, projErr := LoadSettingsFile(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	value, ok := settings.Get(key)
+	if !ok {
+		err := fmt.Errorf("%s: %s", textSettingNotSet, key)
+		return nil, &ProjectError{errOpNotFound, err, err.Error()}
+	}
+	return value, nil
+}
+
+// UpdateSetting sets a field in the .cw-settings file of the project at the given path,
+// refusing to write a value that is invalid for the project type
+func UpdateSetting(projectPath, projectType, key, value string) (*SettingsFile, *ProjectError) {
+	settings, projErr := LoadSettingsFile(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if projectType == "" {
+		_, projectType = determineProjectInfo(projectPath)
+	}
+	projErr = settings.Set(key, value)
+	if projErr != nil {
+		return nil, projErr
+	}
+	issues := validateSetting(key, settings.values[key], projectType)
+	if len(issues) > 0 {
+		err := fmt.Errorf("%s: %s", issues[0].Field, issues[0].Message)
+		return nil, &ProjectError{errOpInvalidSettings, err, err.Error()}
+	}
+	projErr = settings.Save()
+	if projErr != nil {
+		return nil, projErr
+	}
+	return settings, nil
+}
+
+// RemoveSetting removes a field from the .cw-settings file of the project at the given path
+func RemoveSetting(projectPath, key string) (*SettingsFile, *ProjectError) {
+	settings, projErr := LoadSettingsFile(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	projErr = settings.Unset(key)
+	if projErr != nil {
+		return nil, projErr
+	}
+	projErr = settings.Save()
+	if projErr != nil {
+		return nil, projErr
+	}
+	return settings, nil
+}
+
+func validateSetting(key string, value json.RawMessage, projectType string) []SettingsIssue {
+	schema, known := cwSettingsSchema[key]
+	if !known {
+		return []SettingsIssue{{key, textUnknownSetting}}
+	}
+	if len(schema.projectTypes) > 0 && !stringInSlice(projectType, schema.projectTypes) {
+		message := fmt.Sprintf("only valid for project types %s, not %s", strings.Join(schema.projectTypes, ", "), projectType)
+		return []SettingsIssue{{key, message}}
+	}
+
+	var issues []SettingsIssue
+	addIssue := func(message string) {
+		issues = append(issues, SettingsIssue{key, message})
+	}
+
+	switch schema.kind {
+	case settingBool:
+		var b bool
+		if json.Unmarshal(value, &b) != nil {
+			addIssue("must be true or false")
+		}
+	case settingStringList:
+		var list []string
+		if json.Unmarshal(value, &list) != nil {
+			addIssue("must be a list of strings")
+		}
+	default:
+		var s string
+		if json.Unmarshal(value, &s) != nil {
+			addIssue(fmt.Sprintf("must be a string, e.g. %q", exampleSettingValue(schema.kind)))
+			break
+		}
+		if s == "" {
+			break
+		}
+		switch schema.kind {
+		case settingPath:
+			if !strings.HasPrefix(s, "/") || strings.ContainsAny(s, " \t") {
+				addIssue("must be a path beginning with '/' and not containing spaces")
+			}
+		case settingPort:
+			port, err := strconv.Atoi(s)
+			if err != nil || port < 1 || port > 65535 {
+				addIssue("must be a port number between 1 and 65535")
+			}
+		case settingNumber:
+			number, err := strconv.Atoi(s)
+			if err != nil || number < 0 {
+				addIssue("must be a whole number of seconds")
+			}
+		}
+	}
+	return issues
+}
+
+func exampleSettingValue(kind settingKind) string {
+	switch kind {
+	case settingPath:
+		return "/health"
+	case settingPort:
+		return "9080"
+	case settingNumber:
+		return "30"
+	}
+	return "value"
+}
+
+// settingValueFromString converts a value given on the command line into the JSON the schema expects
+func settingValueFromString(key, value string) (json.RawMessage, error) {
+	schema, known := cwSettingsSchema[key]
+	if !known {
+		// keep whatever type the user gave for fields we don't know about
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value), nil
+		}
+		return json.Marshal(value)
+	}
+
+	switch schema.kind {
+	case settingBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", key)
+		}
+		return json.Marshal(b)
+	case settingStringList:
+		list := []string{}
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			if err := json.Unmarshal([]byte(value), &list); err != nil {
+				return nil, fmt.Errorf("%s must be a list of strings", key)
+			}
+		} else {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		return json.Marshal(list)
+	default:
+		var s string
+		if json.Unmarshal([]byte(value), &s) == nil {
+			return json.Marshal(s)
+		}
+		return json.Marshal(value)
+	}
+}
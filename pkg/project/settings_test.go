@@ -0,0 +1,172 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSettings = `{
+    "contextRoot": "",
+    "internalPort": "3000",
+    "healthCheck": "",
+    "ignoredPaths": [
+        "*/node_modules*"
+    ],
+    "customField": {"keep": true}
+}
+`
+
+func TestSettingsFileRoundTrip(t *testing.T) {
+	settings, err := parseSettingsFile("dummy", []byte(testSettings))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"contextRoot", "internalPort", "healthCheck", "ignoredPaths", "customField"}, settings.Keys())
+	assert.Equal(t, testSettings, string(settings.Bytes()))
+}
+
+func TestSettingsFileSet(t *testing.T) {
+	tests := map[string]struct {
+		key       string
+		value     string
+		wantValue string
+	}{
+		"success case: port is stored as a string": {
+			key:       "internalPort",
+			value:     "9080",
+			wantValue: `"9080"`,
+		},
+		"success case: bool is stored as a bool": {
+			key:       "isHttps",
+			value:     "true",
+			wantValue: "true",
+		},
+		"success case: comma separated list": {
+			key:       "ignoredPaths",
+			value:     "a, b",
+			wantValue: "[\n        \"a\",\n        \"b\"\n    ]",
+		},
+		"success case: JSON list": {
+			key:       "mavenProfiles",
+			value:     `["dev"]`,
+			wantValue: "[\n        \"dev\"\n    ]",
+		},
+		"success case: unknown field keeps its JSON type": {
+			key:       "somethingNew",
+			value:     "42",
+			wantValue: "42",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseSettingsFile("dummy", []byte(testSettings))
+			require.Nil(t, err)
+			projErr := settings.Set(test.key, test.value)
+			require.Nil(t, projErr)
+			got, ok := settings.Get(test.key)
+			assert.True(t, ok)
+			assert.Equal(t, test.wantValue, string(got))
+		})
+	}
+
+	t.Run("fail case: invalid bool", func(t *testing.T) {
+		settings, _ := parseSettingsFile("dummy", []byte(testSettings))
+		projErr := settings.Set("isHttps", "yes please")
+		assert.Equal(t, errOpInvalidSettings, projErr.Op)
+	})
+}
+
+func TestSettingsFileUnset(t *testing.T) {
+	settings, _ := parseSettingsFile("dummy", []byte(testSettings))
+	projErr := settings.Unset("internalPort")
+	require.Nil(t, projErr)
+	_, ok := settings.Get("internalPort")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"contextRoot", "healthCheck", "ignoredPaths", "customField"}, settings.Keys())
+
+	projErr = settings.Unset("internalPort")
+	assert.Equal(t, errOpNotFound, projErr.Op)
+}
+
+func TestSettingsFileValidate(t *testing.T) {
+	tests := map[string]struct {
+		in          string
+		projectType string
+		wantIssues  []SettingsIssue
+	}{
+		"success case: valid settings": {
+			in:          `{"internalPort": "3000", "healthCheck": "/health", "internalDebugPort": "", "isHttps": false, "statusPingTimeout": "30"}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{},
+		},
+		"fail case: port is a number": {
+			in:          `{"internalPort": 3000}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{{"internalPort", `must be a string, e.g. "9080"`}},
+		},
+		"fail case: port out of range": {
+			in:          `{"internalPort": "99999"}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{{"internalPort", "must be a port number between 1 and 65535"}},
+		},
+		"fail case: health check is not a path": {
+			in:          `{"healthCheck": "health"}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{{"healthCheck", "must be a path beginning with '/' and not containing spaces"}},
+		},
+		"fail case: maven settings on a node project": {
+			in:          `{"mavenProfiles": []}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{{"mavenProfiles", "only valid for project types liberty, spring, not nodejs"}},
+		},
+		"fail case: unknown field": {
+			in:          `{"internalPrt": "3000"}`,
+			projectType: "nodejs",
+			wantIssues:  []SettingsIssue{{"internalPrt", textUnknownSetting}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings, err := parseSettingsFile("dummy", []byte(test.in))
+			require.Nil(t, err)
+			assert.Equal(t, test.wantIssues, settings.Validate(test.projectType))
+		})
+	}
+}
+
+func TestUpdateSetting(t *testing.T) {
+	projectPath := filepath.Join(testDir, "settingsProject")
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+	os.MkdirAll(projectPath, 0755)
+	ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte(testSettings), 0644)
+
+	t.Run("success case: setting is written to disk", func(t *testing.T) {
+		_, projErr := UpdateSetting(projectPath, "nodejs", "healthCheck", "/health")
+		require.Nil(t, projErr)
+		contents, _ := ioutil.ReadFile(filepath.Join(projectPath, ".cw-settings"))
+		assert.Contains(t, string(contents), `"healthCheck": "/health"`)
+		assert.Contains(t, string(contents), `"customField": {"keep": true}`)
+	})
+
+	t.Run("fail case: invalid setting is not written to disk", func(t *testing.T) {
+		_, projErr := UpdateSetting(projectPath, "nodejs", "internalPort", "http")
+		assert.Equal(t, errOpInvalidSettings, projErr.Op)
+		contents, _ := ioutil.ReadFile(filepath.Join(projectPath, ".cw-settings"))
+		assert.Contains(t, string(contents), `"internalPort": "3000"`)
+	})
+}
@@ -0,0 +1,296 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"golang.org/x/net/proxy"
+	"golang.org/x/net/websocket"
+)
+
+// pfeSocket is a connection to the socket.io endpoint the Codewind server sends project events on, which is
+// what the Codewind IDE plugins listen to. Only the parts of the socket.io 2 protocol needed to receive events
+// are implemented: the engine.io open packet, joining a namespace, pings and event packets.
+type pfeSocket struct {
+	ws        *websocket.Conn
+	namespace string
+	done      chan struct{}
+}
+
+const (
+	engineIOOpen    = "0"
+	engineIOPing    = "2"
+	socketIOConnect = "40"
+	socketIOEvent   = "42"
+
+	defaultSocketPingInterval = 25 * time.Second
+	socketDialTimeout         = 30 * time.Second
+)
+
+// dialPFESocket connects to the socket.io endpoint of a Codewind server and joins a namespace, sending
+// the access token, if there is one, to get through the gatekeeper of remote connections. The socket uses the
+// connection's TLS settings and proxies.
+func dialPFESocket(conInfo *connections.Connection, conURL, namespace, accessToken string) (*pfeSocket, error) {
+	socketURL, err := url.Parse(conURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(socketURL.Scheme, "https") {
+		socketURL.Scheme = "wss"
+	} else {
+		socketURL.Scheme = "ws"
+	}
+	socketURL.Path = "/socket.io/"
+	socketURL.RawQuery = "EIO=3&transport=websocket"
+
+	config, err := websocket.NewConfig(socketURL.String(), conURL)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken != "" {
+		config.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	tlsConfig, conErr := conInfo.TLSConfig()
+	if conErr != nil {
+		return nil, conErr
+	}
+	if tlsConfig == nil {
+		// keep the TLS settings of the global --insecure flag
+		if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok && defaultTransport.TLSClientConfig != nil {
+			tlsConfig = defaultTransport.TLSClientConfig
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+	}
+	config.TlsConfig = tlsConfig
+	conn, err := dialSocketConn(conInfo, socketURL, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// the server opens the session with how often it expects to be pinged
+	var open string
+	if err := websocket.Message.Receive(ws, &open); err != nil {
+		ws.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(open, engineIOOpen) {
+		ws.Close()
+		return nil, errors.New(textSocketNotOpened)
+	}
+	handshake := struct {
+		PingInterval int `json:"pingInterval"`
+	}{}
+	json.Unmarshal([]byte(open[len(engineIOOpen):]), &handshake)
+	pingInterval := time.Duration(handshake.PingInterval) * time.Millisecond
+	if pingInterval <= 0 {
+		pingInterval = defaultSocketPingInterval
+	}
+
+	socket := &pfeSocket{ws: ws, namespace: normaliseNamespace(namespace), done: make(chan struct{})}
+	if socket.namespace != "/" {
+		if err := websocket.Message.Send(ws, socketIOConnect+socket.namespace); err != nil {
+			ws.Close()
+			return nil, err
+		}
+	}
+	go socket.ping(pingInterval)
+	return socket, nil
+}
+
+// dialSocketConn opens the network connection for a socket, through the proxy the connection uses for the
+// equivalent http or https URL, and starts TLS on it for a wss socket
+func dialSocketConn(conInfo *connections.Connection, socketURL *url.URL, tlsConfig *tls.Config) (net.Conn, error) {
+	requestURL := *socketURL
+	requestURL.Scheme = "http"
+	if socketURL.Scheme == "wss" {
+		requestURL.Scheme = "https"
+	}
+	req, err := http.NewRequest("GET", requestURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, err := conInfo.ProxyFunc()(req)
+	if err != nil {
+		return nil, err
+	}
+
+	address := hostWithPort(socketURL.Host, requestURL.Scheme)
+	dialer := &net.Dialer{Timeout: socketDialTimeout}
+	var conn net.Conn
+	switch {
+	case proxyURL == nil:
+		conn, err = dialer.Dial("tcp", address)
+	case strings.EqualFold(proxyURL.Scheme, "socks5"):
+		var socksDialer proxy.Dialer
+		socksDialer, err = proxy.FromURL(proxyURL, dialer)
+		if err == nil {
+			conn, err = socksDialer.Dial("tcp", address)
+		}
+	default:
+		conn, err = dialHTTPProxyTunnel(dialer, proxyURL, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if socketURL.Scheme != "wss" {
+		return conn, nil
+	}
+
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = socketURL.Hostname()
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	tlsConn.SetDeadline(time.Now().Add(socketDialTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// dialHTTPProxyTunnel opens a tunnel to an address through an http or https proxy with a CONNECT request
+func dialHTTPProxyTunnel(dialer *net.Dialer, proxyURL *url.URL, address string) (net.Conn, error) {
+	conn, err := dialer.Dial("tcp", hostWithPort(proxyURL.Host, proxyURL.Scheme))
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(proxyURL.Scheme, "https") {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+	}
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	conn.SetDeadline(time.Now().Add(socketDialTimeout))
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// the proxy sends nothing after its response until the websocket handshake is sent
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %s", textSocketProxyRefused, resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// hostWithPort returns a host with the default port of the scheme when it has no port
+func hostWithPort(host, scheme string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	if strings.EqualFold(scheme, "https") {
+		return net.JoinHostPort(strings.Trim(host, "[]"), "443")
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), "80")
+}
+
+// normaliseNamespace returns a socket.io namespace with a leading slash, or "/" for the root namespace
+func normaliseNamespace(namespace string) string {
+	namespace = strings.TrimSpace(namespace)
+	if !strings.HasPrefix(namespace, "/") {
+		namespace = "/" + namespace
+	}
+	return namespace
+}
+
+// ping keeps the session open until the socket is closed
+func (socket *pfeSocket) ping(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-socket.done:
+			return
+		case <-ticker.C:
+			if websocket.Message.Send(socket.ws, engineIOPing) != nil {
+				return
+			}
+		}
+	}
+}
+
+// readEvent returns the name and data of the next event sent on the socket's namespace, waiting no later than
+// the deadline if it is set
+func (socket *pfeSocket) readEvent(deadline time.Time) (string, json.RawMessage, error) {
+	socket.ws.SetReadDeadline(deadline)
+	for {
+		var packet string
+		if err := websocket.Message.Receive(socket.ws, &packet); err != nil {
+			return "", nil, err
+		}
+		if !strings.HasPrefix(packet, socketIOEvent) {
+			continue
+		}
+		// events on a namespace other than the root one are prefixed by the namespace and a comma
+		packet = packet[len(socketIOEvent):]
+		namespace := "/"
+		if strings.HasPrefix(packet, "/") {
+			comma := strings.IndexByte(packet, ',')
+			if comma < 0 {
+				continue
+			}
+			namespace, packet = packet[:comma], packet[comma+1:]
+		}
+		if namespace != socket.namespace {
+			continue
+		}
+		event := []json.RawMessage{}
+		if json.Unmarshal([]byte(packet), &event) != nil || len(event) < 2 {
+			continue
+		}
+		var name string
+		json.Unmarshal(event[0], &name)
+		return name, event[1], nil
+	}
+}
+
+// Close ends the session
+func (socket *pfeSocket) Close() {
+	close(socket.done)
+	socket.ws.Close()
+}
@@ -93,10 +93,16 @@ type (
 
 // SyncProject syncs a project with its remote connection
 func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
-	var currentSyncTime = time.Now().UnixNano() / 1000000
 	projectPath := strings.TrimSpace(c.String("path"))
 	projectID := strings.TrimSpace(c.String("id"))
 	synctime := int64(c.Int("time"))
+	return SyncProjectByID(projectPath, projectID, synctime)
+}
+
+// SyncProjectByID syncs the files of a project changed since synctime, in milliseconds, with its remote connection.
+// A synctime of 0 syncs every file.
+func SyncProjectByID(projectPath, projectID string, synctime int64) (*SyncResponse, *ProjectError) {
+	var currentSyncTime = time.Now().UnixNano() / 1000000
 
 	conID, projErr := GetConnectionID(projectID)
 
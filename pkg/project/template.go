@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+)
+
+var templateNameCleaner = regexp.MustCompile("[^a-z0-9]")
+
+// GetTemplateForConnection resolves a template label or URL to a single template
+// known by the given connection
+func GetTemplateForConnection(conID, templateName string) (*apiroutes.Template, *ProjectError) {
+	templates, err := apiroutes.GetTemplates(conID, "", false)
+	if err != nil {
+		return nil, &ProjectError{errOpTemplateNotFound, err, err.Error()}
+	}
+	return ResolveTemplate(templates, templateName)
+}
+
+// ResolveTemplate picks a single template from the given list using its label or URL.
+// Exact matches (ignoring case) win, otherwise a template is picked if it is the
+// only one whose label contains the given name once punctuation and spacing are ignored
+func ResolveTemplate(templates []apiroutes.Template, templateName string) (*apiroutes.Template, *ProjectError) {
+	name := strings.TrimSpace(templateName)
+	if name == "" {
+		err := errors.New(textNoTemplateName)
+		return nil, &ProjectError{errOpTemplateNotFound, err, err.Error()}
+	}
+
+	var exactMatches []apiroutes.Template
+	for _, template := range templates {
+		if strings.EqualFold(template.URL, name) || strings.EqualFold(template.Label, name) {
+			exactMatches = append(exactMatches, template)
+		}
+	}
+	if len(exactMatches) == 1 {
+		return &exactMatches[0], nil
+	}
+	if len(exactMatches) > 1 {
+		return nil, ambiguousTemplateError(name, exactMatches)
+	}
+
+	cleanName := cleanTemplateName(name)
+	var fuzzyMatches []apiroutes.Template
+	if cleanName != "" {
+		for _, template := range templates {
+			if strings.Contains(cleanTemplateName(template.Label), cleanName) {
+				fuzzyMatches = append(fuzzyMatches, template)
+			}
+		}
+	}
+	if len(fuzzyMatches) == 1 {
+		return &fuzzyMatches[0], nil
+	}
+	if len(fuzzyMatches) > 1 {
+		return nil, ambiguousTemplateError(name, fuzzyMatches)
+	}
+
+	err := fmt.Errorf("%s: %q", textTemplateNotFound, name)
+	return nil, &ProjectError{errOpTemplateNotFound, err, err.Error()}
+}
+
+// ambiguousTemplateError lists the candidate templates so the user can pick one by its URL
+func ambiguousTemplateError(name string, candidates []apiroutes.Template) *ProjectError {
+	descriptions := make([]string, 0, len(candidates))
+	for _, template := range candidates {
+		description := template.Label
+		if template.Source != "" {
+			description += " [" + template.Source + "]"
+		}
+		descriptions = append(descriptions, description+" ("+template.URL+")")
+	}
+	err := fmt.Errorf("%s %q, use the template URL to choose one of: %s", textTemplateAmbiguous, name, strings.Join(descriptions, ", "))
+	return &ProjectError{errOpTemplateAmbiguous, err, err.Error()}
+}
+
+func cleanTemplateName(name string) string {
+	return templateNameCleaner.ReplaceAllString(strings.ToLower(name), "")
+}
@@ -0,0 +1,100 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	nodeTemplate := apiroutes.Template{Label: "Node.js Express", URL: "https://github.com/codewind-resources/nodeExpressTemplate", Source: "Default templates"}
+	springTemplate := apiroutes.Template{Label: "Spring Boot®", URL: "https://github.com/codewind-resources/springJavaTemplate", Source: "Default templates"}
+	libertyTemplate := apiroutes.Template{Label: "Open Liberty", URL: "https://github.com/codewind-resources/openLibertyTemplate", Source: "Default templates"}
+	otherLibertyTemplate := apiroutes.Template{Label: "Open Liberty", URL: "https://github.com/kabanero-io/openLibertyTemplate", Source: "Kabanero"}
+	templates := []apiroutes.Template{nodeTemplate, springTemplate, libertyTemplate}
+
+	successTests := map[string]struct {
+		in   string
+		want apiroutes.Template
+	}{
+		"success case: exact label": {
+			in:   "Node.js Express",
+			want: nodeTemplate,
+		},
+		"success case: label ignoring case": {
+			in:   "node.js express",
+			want: nodeTemplate,
+		},
+		"success case: URL": {
+			in:   "https://github.com/codewind-resources/springJavaTemplate",
+			want: springTemplate,
+		},
+		"success case: partial label ignoring punctuation": {
+			in:   "nodejs",
+			want: nodeTemplate,
+		},
+		"success case: partial label ignoring spacing": {
+			in:   "springboot",
+			want: springTemplate,
+		},
+	}
+	for name, test := range successTests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveTemplate(templates, test.in)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, *got)
+		})
+	}
+
+	failureTests := map[string]struct {
+		templates []apiroutes.Template
+		in        string
+		wantOp    string
+	}{
+		"fail case: empty name": {
+			templates: templates,
+			in:        " ",
+			wantOp:    errOpTemplateNotFound,
+		},
+		"fail case: no match": {
+			templates: templates,
+			in:        "python",
+			wantOp:    errOpTemplateNotFound,
+		},
+		"fail case: partial label matches more than one template": {
+			templates: templates,
+			in:        "o",
+			wantOp:    errOpTemplateAmbiguous,
+		},
+		"fail case: label used by more than one source": {
+			templates: append(templates, otherLibertyTemplate),
+			in:        "Open Liberty",
+			wantOp:    errOpTemplateAmbiguous,
+		},
+	}
+	for name, test := range failureTests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveTemplate(test.templates, test.in)
+			assert.Nil(t, got)
+			assert.Equal(t, test.wantOp, err.Op)
+		})
+	}
+
+	t.Run("fail case: ambiguous error lists the candidate URLs", func(t *testing.T) {
+		_, err := ResolveTemplate(append(templates, otherLibertyTemplate), "open liberty")
+		assert.Contains(t, err.Desc, libertyTemplate.URL)
+		assert.Contains(t, err.Desc, otherLibertyTemplate.URL)
+	})
+}
@@ -39,5 +39,7 @@ func Unbind(httpClient utils.HTTPClient, connection *connections.Connection, url
 		return &ProjectError{errOpUnbind, err, err.Error()}
 	}
 
+	// We can ignore errors as a stale index entry is only used to find the project's connection
+	RemoveProjectFromIndex(projectID)
 	return nil
 }
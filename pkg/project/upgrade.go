@@ -17,75 +17,212 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 )
 
-// UpgradeProjects : Upgrades Projects
-func UpgradeProjects(oldDir string) (*map[string]interface{}, *ProjectError) {
+type (
+	// UpgradeReport represents the result of upgrading the projects in a workspace
+	UpgradeReport struct {
+		DryRun       bool   `json:"dryRun"`
+		ConnectionID string `json:"connectionID"`
+		BackupDir    string `json:"backupDir,omitempty"`
+		// Migrated names the projects that were, or in a dry run would be, bound to the connection. It is kept
+		// only for backwards compatibility with the output of earlier versions, MigratedProjects also has their IDs.
+		Migrated         []string          `json:"migrated"`
+		MigratedProjects []MigratedProject `json:"migratedProjects"`
+		Skipped          []SkippedProject  `json:"skipped"`
+		Failed           []FailedProject   `json:"failed"`
+	}
+
+	// MigratedProject is a migrated project and the ID it was bound with, which is empty in a dry run
+	MigratedProject struct {
+		ProjectName string `json:"projectName"`
+		ProjectID   string `json:"projectID,omitempty"`
+	}
+
+	// SkippedProject is a project that did not need upgrading
+	SkippedProject struct {
+		ProjectName string `json:"projectName"`
+		Reason      string `json:"reason"`
+	}
+
+	// FailedProject is a project that could not be upgraded
+	FailedProject struct {
+		ProjectName string `json:"projectName"`
+		Error       string `json:"error"`
+	}
+
+	// upgradeCandidate is a project described by an .inf file in the workspace
+	upgradeCandidate struct {
+		infPath     string
+		name        string
+		language    string
+		projectType string
+		location    string
+	}
+)
+
+// UpgradeProjects : Binds the projects described by the .inf files in a workspace to the given connection,
+// skipping projects that are already bound. Unless this is a dry run the .inf files are backed up first
+// and removed once their project has been bound.
+func UpgradeProjects(httpClient utils.HTTPClient, oldDir string, conID string, dryRun bool) (*UpgradeReport, *ProjectError) {
+	return upgradeProjects(httpClient, oldDir, conID, dryRun, getConnectionAndURL)
+}
+
+// upgradeProjects upgrades the projects in a workspace, looking up the connection with getConnection
+func upgradeProjects(httpClient utils.HTTPClient, oldDir string, conID string, dryRun bool, getConnection func(string) (*connections.Connection, string, *ProjectError)) (*UpgradeReport, *ProjectError) {
 	// Check to see if the workspace exists
 	_, err := os.Stat(oldDir)
 	if err != nil {
 		return nil, &ProjectError{errBadPath, err, err.Error()}
 	}
-	projectDir := oldDir + "/.projects/"
+	projectDir := filepath.Join(oldDir, ".projects")
 	// Check to see if the .projects dir exists
 	_, fileerr := os.Stat(projectDir)
 	if fileerr != nil {
 		return nil, &ProjectError{textNoProjects, fileerr, fileerr.Error()}
 	}
 
-	migrationStatus := make(map[string]interface{})
-	migrationStatus["migrated"] = make([]string, 0)
-	migrationStatus["failed"] = make([]interface{}, 0)
+	report := UpgradeReport{
+		DryRun:           dryRun,
+		ConnectionID:     conID,
+		Migrated:         []string{},
+		MigratedProjects: []MigratedProject{},
+		Skipped:          []SkippedProject{},
+		Failed:           []FailedProject{},
+	}
 
-	filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+	infPaths, projErr := findInfFiles(projectDir)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	candidates := []upgradeCandidate{}
+	for _, infPath := range infPaths {
+		candidate, err := readUpgradeCandidate(infPath, oldDir)
+		if err != nil {
+			report.Failed = append(report.Failed, FailedProject{candidate.name, err.Error()})
+			continue
+		}
+		candidates = append(candidates, *candidate)
+	}
+	if len(candidates) == 0 {
+		return &report, nil
+	}
+
+	// Find the projects already bound to the connection so a re-run does not bind them again
+	conInfo, conURL, projErr := getConnection(conID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	boundProjects, projErr := GetAll(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+	boundProjectIDs := make(map[string]string)
+	for _, project := range boundProjects {
+		boundProjectIDs[project.Name] = project.ProjectID
+	}
+
+	if !dryRun {
+		report.BackupDir = filepath.Join(oldDir, ".projects-backup-"+strconv.FormatInt(utils.CreateTimestamp(), 10))
+		err := backupInfFiles(infPaths, oldDir, report.BackupDir)
 		if err != nil {
-			err = errors.New(textUpgradeError)
-			return &ProjectError{errOpFileParse, err, textUpgradeError}
+			return nil, &ProjectError{errOpFileWrite, err, err.Error()}
 		}
+	}
 
-		if !info.IsDir() {
-			file, err := ioutil.ReadFile(path)
-			if err != nil {
-				return nil
-			}
-
-			// only read .inf files
-			extension := filepath.Ext(path)
-			if extension != ".inf" {
-				return nil
-			}
-
-			var result map[string]string
-			err = json.Unmarshal([]byte(file), &result)
-			if err != nil {
-				return nil
-			}
-
-			language := result["language"]
-			projectType := result["projectType"]
-			name := result["name"]
-			location := oldDir + "/" + name
-
-			if language != "" && projectType != "" && name != "" && location != "" {
-				_, bindErr := Bind(location, name, language, projectType, "local")
-				if bindErr != nil {
-					errResponse := make(map[string]string)
-					errResponse["projectName"] = name
-					errResponse["error"] = bindErr.Desc
-					migrationStatus["failed"] = append(migrationStatus["failed"].([]interface{}), &errResponse)
-				} else {
-					// attempt to delete the inf file
-					os.Remove(path)
-					migrationStatus["migrated"] = append(migrationStatus["migrated"].([]string), name)
-				}
-			} else {
-				errResponse := make(map[string]string)
-				errResponse["projectName"] = name
-				errResponse["error"] = "Unable to upgrade project, failed to determine project details"
-				migrationStatus["failed"] = append(migrationStatus["failed"].([]interface{}), &errResponse)
-			}
+	for _, candidate := range candidates {
+		if projectID, isBound := boundProjectIDs[candidate.name]; isBound {
+			report.Skipped = append(report.Skipped, SkippedProject{candidate.name, textProjectAlreadyBound + " " + conID + " with ID " + projectID})
+			continue
+		}
+		if dryRun {
+			report.Migrated = append(report.Migrated, candidate.name)
+			report.MigratedProjects = append(report.MigratedProjects, MigratedProject{ProjectName: candidate.name})
+			continue
+		}
+		bindResponse, bindErr := Bind(candidate.location, candidate.name, candidate.language, candidate.projectType, conID)
+		if bindErr != nil {
+			report.Failed = append(report.Failed, FailedProject{candidate.name, bindErr.Desc})
+			continue
+		}
+		// attempt to delete the inf file, it has been backed up
+		os.Remove(candidate.infPath)
+		report.Migrated = append(report.Migrated, candidate.name)
+		report.MigratedProjects = append(report.MigratedProjects, MigratedProject{candidate.name, bindResponse.ProjectID})
+	}
+	return &report, nil
+}
+
+// findInfFiles returns the paths of the .inf files in the given directory and its subdirectories
+func findInfFiles(projectDir string) ([]string, *ProjectError) {
+	infPaths := []string{}
+	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".inf" {
+			infPaths = append(infPaths, path)
 		}
 		return nil
 	})
-	return &migrationStatus, nil
+	if err != nil {
+		err = errors.New(textUpgradeError)
+		return nil, &ProjectError{errOpFileParse, err, textUpgradeError}
+	}
+	return infPaths, nil
+}
+
+// readUpgradeCandidate reads the project details from an .inf file and checks the project directory exists
+func readUpgradeCandidate(infPath string, oldDir string) (*upgradeCandidate, error) {
+	candidate := upgradeCandidate{infPath: infPath}
+	file, err := ioutil.ReadFile(infPath)
+	if err != nil {
+		return &candidate, err
+	}
+
+	var result map[string]string
+	err = json.Unmarshal([]byte(file), &result)
+	if err != nil {
+		return &candidate, err
+	}
+
+	candidate.language = result["language"]
+	candidate.projectType = result["projectType"]
+	candidate.name = result["name"]
+	if candidate.language == "" || candidate.projectType == "" || candidate.name == "" {
+		return &candidate, errors.New(textUpgradeMissingInfo)
+	}
+
+	candidate.location = oldDir + "/" + candidate.name
+	_, err = os.Stat(candidate.location)
+	if err != nil {
+		return &candidate, err
+	}
+	return &candidate, nil
+}
+
+// backupInfFiles copies the given .inf files into the backup directory, at the same path relative to the
+// backup directory as they have relative to the workspace, so .inf files with the same name in different
+// directories are all kept
+func backupInfFiles(infPaths []string, workspaceDir string, backupDir string) error {
+	for _, infPath := range infPaths {
+		relativePath, err := filepath.Rel(workspaceDir, infPath)
+		if err != nil {
+			return err
+		}
+		backupPath := filepath.Join(backupDir, relativePath)
+		err = os.MkdirAll(filepath.Dir(backupPath), 0755)
+		if err == nil {
+			err = utils.CopyFile(infPath, backupPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -12,10 +12,18 @@
 package project
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,34 +32,38 @@ func Test_UpgradeProjects(t *testing.T) {
 
 	/*
 		Currently unused, awaiting valid output testing
-			validOuput := make(map[string]interface{})
-			validOuput["migrated"] = []string{"valid-project"}
-			validOuput["failed"] = make([]interface{}, 0)
+			validOuput := UpgradeReport{ConnectionID: "local", Migrated: []string{"valid-project"}, ...}
 	*/
 
-	missingInfoOutput := make(map[string]interface{})
-	missingInfoOutput["migrated"] = make([]string, 0)
-	missingInfoOutput["failed"] = []interface{}{
-		&map[string]string{
-			"projectName": "missing-project-info",
-			"error":       "Unable to upgrade project, failed to determine project details",
+	emptyOutput := UpgradeReport{
+		ConnectionID:     "local",
+		Migrated:         make([]string, 0),
+		MigratedProjects: []MigratedProject{},
+		Skipped:          []SkippedProject{},
+		Failed:           make([]FailedProject, 0),
+	}
+
+	missingInfoOutput := emptyOutput
+	missingInfoOutput.Failed = []FailedProject{
+		{
+			ProjectName: "missing-project-info",
+			Error:       "Unable to upgrade project, failed to determine project details",
 		},
 	}
 
-	missingDirectoryOutput := make(map[string]interface{})
-	missingDirectoryOutput["migrated"] = make([]string, 0)
+	missingDirectoryOutput := emptyOutput
 	if runtime.GOOS == "windows" {
-		missingDirectoryOutput["failed"] = []interface{}{
-			&map[string]string{
-				"projectName": "missing-project-dir",
-				"error":       "CreateFile ../../resources/workspaces/error-projects/missing-project-dir/missing-project-dir: The system cannot find the file specified.",
+		missingDirectoryOutput.Failed = []FailedProject{
+			{
+				ProjectName: "missing-project-dir",
+				Error:       "CreateFile ../../resources/workspaces/error-projects/missing-project-dir/missing-project-dir: The system cannot find the file specified.",
 			},
 		}
 	} else {
-		missingDirectoryOutput["failed"] = []interface{}{
-			&map[string]string{
-				"projectName": "missing-project-dir",
-				"error":       "stat ../../resources/workspaces/error-projects/missing-project-dir/missing-project-dir: no such file or directory",
+		missingDirectoryOutput.Failed = []FailedProject{
+			{
+				ProjectName: "missing-project-dir",
+				Error:       "stat ../../resources/workspaces/error-projects/missing-project-dir/missing-project-dir: no such file or directory",
 			},
 		}
 	}
@@ -59,7 +71,7 @@ func Test_UpgradeProjects(t *testing.T) {
 	tests := map[string]struct {
 		workspaceDir   string
 		expectsErr     bool
-		expectedOutput *map[string]interface{}
+		expectedOutput *UpgradeReport
 	}{
 		"success case: missing directory should return bad path error": {
 			workspaceDir:   "/does-not-exist/",
@@ -74,7 +86,7 @@ func Test_UpgradeProjects(t *testing.T) {
 		"success case: empty .projects directory returns empty output and no errors": {
 			workspaceDir:   "/empty/",
 			expectsErr:     false,
-			expectedOutput: &map[string]interface{}{"migrated": []string{}, "failed": []interface{}{}},
+			expectedOutput: &emptyOutput,
 		},
 		/*
 			TODO: requires API call to bind project. Needs to be mocked.
@@ -98,7 +110,7 @@ func Test_UpgradeProjects(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			path := path.Join(workspaceFolder, test.workspaceDir)
-			response, err := UpgradeProjects(path)
+			response, err := UpgradeProjects(http.DefaultClient, path, "local", false)
 
 			assert.Exactly(t, test.expectedOutput, response, "upgrade gave incorrect response")
 			if test.expectsErr {
@@ -110,3 +122,72 @@ func Test_UpgradeProjects(t *testing.T) {
 	}
 
 }
+
+func Test_BackupInfFiles(t *testing.T) {
+	os.RemoveAll(testDir)
+	defer os.RemoveAll(testDir)
+
+	projectDir := filepath.Join(testDir, ".projects")
+	infPaths := []string{
+		filepath.Join(projectDir, "one.inf"),
+		filepath.Join(projectDir, "two.inf"),
+		filepath.Join(projectDir, "nested", "one.inf"),
+	}
+	for _, infPath := range infPaths {
+		os.MkdirAll(filepath.Dir(infPath), 0755)
+		ioutil.WriteFile(infPath, []byte(`{"name": "`+infPath+`"}`), 0644)
+	}
+
+	backupDir := filepath.Join(testDir, ".projects-backup")
+	err := backupInfFiles(infPaths, testDir, backupDir)
+	assert.Nil(t, err)
+	for _, infPath := range infPaths {
+		original, _ := ioutil.ReadFile(infPath)
+		relativePath, _ := filepath.Rel(testDir, infPath)
+		backup, err := ioutil.ReadFile(filepath.Join(backupDir, relativePath))
+		assert.Nil(t, err)
+		assert.Equal(t, original, backup)
+	}
+}
+
+func Test_UpgradeProjectsWithBoundProjects(t *testing.T) {
+	workspace := filepath.Join(testDir, "workspace")
+	infPath := filepath.Join(workspace, ".projects", "valid-project.inf")
+	mockConnection := func(conID string) (*connections.Connection, string, *ProjectError) {
+		return &connections.Connection{ID: conID}, "dummyurl", nil
+	}
+	mockGetAll := func(projects []Project) *security.ClientMockAuthenticate {
+		jsonResponse, _ := json.Marshal(projects)
+		return &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(jsonResponse))}
+	}
+	setUp := func() {
+		os.RemoveAll(testDir)
+		os.MkdirAll(filepath.Join(workspace, "valid-project"), 0755)
+		os.MkdirAll(filepath.Dir(infPath), 0755)
+		ioutil.WriteFile(infPath, []byte(`{"name": "valid-project", "language": "javascript", "projectType": "nodejs"}`), 0644)
+	}
+	defer os.RemoveAll(testDir)
+
+	t.Run("success case: a dry run reports the projects to migrate without changing anything", func(t *testing.T) {
+		setUp()
+		response, err := upgradeProjects(mockGetAll([]Project{}), workspace, "local", true, mockConnection)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"valid-project"}, response.Migrated)
+		assert.Equal(t, []MigratedProject{{ProjectName: "valid-project"}}, response.MigratedProjects)
+		assert.Empty(t, response.BackupDir)
+		assert.True(t, fileExists(infPath))
+		files, _ := ioutil.ReadDir(workspace)
+		assert.Len(t, files, 2, "a backup directory was created")
+	})
+
+	t.Run("success case: a re-run skips projects that are already bound", func(t *testing.T) {
+		setUp()
+		bound := []Project{{ProjectID: "1234", Name: "valid-project"}}
+		response, err := upgradeProjects(mockGetAll(bound), workspace, "local", false, mockConnection)
+		assert.Nil(t, err)
+		assert.Empty(t, response.Migrated)
+		assert.Empty(t, response.MigratedProjects)
+		assert.Equal(t, []SkippedProject{{"valid-project", textProjectAlreadyBound + " local with ID 1234"}}, response.Skipped)
+		assert.True(t, fileExists(infPath))
+	})
+}
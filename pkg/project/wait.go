@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// WaitOptions selects the state to wait for and how long to wait
+	WaitOptions struct {
+		For       string        // "started", "stopped" or "built"
+		StartMode string        // if set, the project must also be in this start mode, e.g. debug
+		Timeout   time.Duration // give up after this long
+		Interval  time.Duration // time between the first polls, doubled after each poll up to maxWaitInterval
+	}
+
+	// ProjectStatus is the state of a project at a point in time
+	ProjectStatus struct {
+		ProjectID   string `json:"projectID"`
+		AppStatus   string `json:"appStatus"`
+		BuildStatus string `json:"buildStatus"`
+		Time        string `json:"time"`
+	}
+)
+
+const (
+	waitForStarted = "started"
+	waitForStopped = "stopped"
+	waitForBuilt   = "built"
+
+	buildStatusSuccess = "success"
+	buildStatusFailed  = "failed"
+
+	defaultWaitInterval = time.Second
+	maxWaitInterval     = 10 * time.Second
+)
+
+// WaitForProject polls a project until it reaches the requested state, calling the handler each time
+// its app or build status changes. It returns the final status, or an error with the Op
+// proj_wait_timeout if the timeout passes first and proj_build_failed if the project's build fails.
+// The first poll may return the result of the previous build, so a successful or failed build status
+// only counts once a build has been seen in progress or the build status has changed.
+func WaitForProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL, projectID string, options WaitOptions, handler func(ProjectStatus)) (*ProjectStatus, *ProjectError) {
+	if options.For != waitForStarted && options.For != waitForStopped && options.For != waitForBuilt {
+		err := fmt.Errorf("%s: %s", textInvalidWaitState, options.For)
+		return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	if options.Timeout <= 0 {
+		err := errors.New(textInvalidWaitTimeout)
+		return nil, &ProjectError{ErrOpInvalidOptions, err, err.Error()}
+	}
+	interval := options.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+
+	deadline := time.Now().Add(options.Timeout)
+	var first, last *ProjectStatus
+	newBuild := false
+	for {
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return last, projErr
+		}
+		status := ProjectStatus{
+			ProjectID:   projectID,
+			AppStatus:   project.AppStatus,
+			BuildStatus: project.BuildStatus,
+			Time:        time.Now().UTC().Format(time.RFC3339),
+		}
+		if last == nil || last.AppStatus != status.AppStatus || last.BuildStatus != status.BuildStatus {
+			handler(status)
+		}
+		last = &status
+		if first == nil {
+			first = &status
+		}
+		if !buildFinished(status.BuildStatus) || status.BuildStatus != first.BuildStatus {
+			newBuild = true
+		}
+
+		if projectReached(options.For, status, newBuild) && (options.StartMode == "" || project.StartMode == options.StartMode) {
+			return last, nil
+		}
+		if options.For != waitForStopped && newBuild && status.BuildStatus == buildStatusFailed {
+			err := errors.New(textBuildFailed)
+			return last, &ProjectError{ErrOpBuildFailed, err, err.Error()}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			err := fmt.Errorf("%s %s after %s", textWaitTimeout, options.For, options.Timeout)
+			return last, &ProjectError{ErrOpWaitTimeout, err, err.Error()}
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxWaitInterval {
+			interval = maxWaitInterval
+		}
+	}
+}
+
+// projectReached reports whether a project is in the state being waited for, where a
+// project is only built once a new build has been seen
+func projectReached(waitFor string, status ProjectStatus, newBuild bool) bool {
+	switch waitFor {
+	case waitForStarted:
+		return status.AppStatus == "started"
+	case waitForStopped:
+		return status.AppStatus == "stopped"
+	case waitForBuilt:
+		return newBuild && status.BuildStatus == buildStatusSuccess
+	}
+	return false
+}
+
+// buildFinished reports whether a build status is the result of a finished build
+func buildFinished(buildStatus string) bool {
+	return buildStatus == buildStatusSuccess || buildStatus == buildStatusFailed
+}
@@ -0,0 +1,140 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockProjectStates returns each of its response bodies in turn, then repeats the last one
+type clientMockProjectStates struct {
+	bodies []string
+	calls  int
+}
+
+func (c *clientMockProjectStates) Do(req *http.Request) (*http.Response, error) {
+	body := c.bodies[len(c.bodies)-1]
+	if c.calls < len(c.bodies) {
+		body = c.bodies[c.calls]
+	}
+	c.calls++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+func TestWaitForProject(t *testing.T) {
+	mockConnection := connections.Connection{ID: "local"}
+	queued := `{"projectID": "mockID", "appStatus": "stopped", "buildStatus": "queued"}`
+	building := `{"projectID": "mockID", "appStatus": "stopped", "buildStatus": "inProgress"}`
+	built := `{"projectID": "mockID", "appStatus": "starting", "buildStatus": "success"}`
+	started := `{"projectID": "mockID", "appStatus": "started", "buildStatus": "success"}`
+	failed := `{"projectID": "mockID", "appStatus": "stopped", "buildStatus": "failed"}`
+
+	tests := map[string]struct {
+		bodies          []string
+		waitFor         string
+		wantOp          string
+		wantAppStatus   string
+		wantTransitions int
+	}{
+		"success case: wait for project to start": {
+			bodies:          []string{queued, building, building, built, started},
+			waitFor:         "started",
+			wantAppStatus:   "started",
+			wantTransitions: 4,
+		},
+		"success case: wait for project to be built": {
+			bodies:          []string{building, built, started},
+			waitFor:         "built",
+			wantAppStatus:   "starting",
+			wantTransitions: 2,
+		},
+		"success case: project is already stopped": {
+			bodies:          []string{failed},
+			waitFor:         "stopped",
+			wantAppStatus:   "stopped",
+			wantTransitions: 1,
+		},
+		"success case: a previous build's success is not accepted": {
+			bodies:          []string{started, building, built},
+			waitFor:         "built",
+			wantAppStatus:   "starting",
+			wantTransitions: 3,
+		},
+		"success case: a previous build's failure does not end the wait": {
+			bodies:          []string{failed, building, built, started},
+			waitFor:         "started",
+			wantAppStatus:   "started",
+			wantTransitions: 4,
+		},
+		"fail case: build fails": {
+			bodies:          []string{building, failed},
+			waitFor:         "started",
+			wantOp:          ErrOpBuildFailed,
+			wantAppStatus:   "stopped",
+			wantTransitions: 2,
+		},
+		"fail case: timed out": {
+			bodies:          []string{queued, building},
+			waitFor:         "started",
+			wantOp:          ErrOpWaitTimeout,
+			wantAppStatus:   "stopped",
+			wantTransitions: 2,
+		},
+		"fail case: no new build": {
+			bodies:          []string{built},
+			waitFor:         "built",
+			wantOp:          ErrOpWaitTimeout,
+			wantAppStatus:   "starting",
+			wantTransitions: 1,
+		},
+		"fail case: unknown state": {
+			bodies:  []string{started},
+			waitFor: "running",
+			wantOp:  ErrOpInvalidOptions,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &clientMockProjectStates{bodies: test.bodies}
+			options := WaitOptions{For: test.waitFor, Timeout: 50 * time.Millisecond, Interval: time.Millisecond}
+			transitions := []ProjectStatus{}
+			status, projErr := WaitForProject(mockClient, &mockConnection, "dummyurl", "mockID", options, func(status ProjectStatus) {
+				transitions = append(transitions, status)
+			})
+			if test.wantOp != "" {
+				assert.Equal(t, test.wantOp, projErr.Op)
+			} else {
+				assert.Nil(t, projErr)
+			}
+			if test.wantAppStatus != "" {
+				assert.Equal(t, test.wantAppStatus, status.AppStatus)
+			}
+			assert.Len(t, transitions, test.wantTransitions)
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 2, ExitCode(&ProjectError{Op: ErrOpWaitTimeout}))
+	assert.Equal(t, 3, ExitCode(&ProjectError{Op: ErrOpBuildFailed}))
+	assert.Equal(t, 1, ExitCode(&ProjectError{Op: errOpNotFound}))
+}
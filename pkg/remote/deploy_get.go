@@ -27,6 +27,7 @@ type ExistingDeployment struct {
 	Version           string `json:"codewindVersion"`
 	InstallDate       string `json:"installTime"`
 	CodewindAuthRealm string `json:"codewindAuthRealm"`
+	GatekeeperURL     string `json:"gatekeeperURL,omitempty"`
 }
 
 // K8sAPI is the k8s client called by the function
@@ -71,7 +72,7 @@ func (client K8sAPI) findDeployments(namespace string) ([]ExistingDeployment, *R
 	var RemoteInstalls []ExistingDeployment
 	for _, deployment := range deployments.Items {
 		installTime := deployment.GetCreationTimestamp().Format(time.RFC1123)
-		var keycloakAddress, cwVersion, authRealm string
+		var keycloakAddress, cwVersion, authRealm, gatekeeperHost, gatekeeperAddress string
 		// ensure there are containers in the list, to avoid index errors
 		if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
 			env := containers[0].Env
@@ -85,8 +86,14 @@ func (client K8sAPI) findDeployments(namespace string) ([]ExistingDeployment, *R
 				if e.Name == "CODEWIND_AUTH_REALM" {
 					authRealm = e.Value
 				}
+				if e.Name == "CHE_INGRESS_HOST" {
+					gatekeeperHost = e.Value
+				}
 			}
 		}
+		if gatekeeperHost != "" {
+			gatekeeperAddress = client.gatekeeperScheme(deployment.GetNamespace(), gatekeeperHost) + "://" + gatekeeperHost
+		}
 
 		deployInfo := ExistingDeployment{
 			Namespace:         deployment.GetNamespace(),
@@ -95,9 +102,41 @@ func (client K8sAPI) findDeployments(namespace string) ([]ExistingDeployment, *R
 			CodewindAuthRealm: authRealm,
 			Version:           cwVersion,
 			InstallDate:       installTime,
+			GatekeeperURL:     gatekeeperAddress,
 		}
 		RemoteInstalls = append(RemoteInstalls, deployInfo)
 	}
 
 	return RemoteInstalls, nil
 }
+
+// gatekeeperScheme returns the scheme the gatekeeper host is served on, which is http when its ingress
+// has no TLS settings for it. Hosts without an ingress, such as OpenShift routes, are served over https.
+func (client K8sAPI) gatekeeperScheme(namespace, host string) string {
+	ingresses, err := client.clientset.ExtensionsV1beta1().Ingresses(namespace).List(v1.ListOptions{})
+	if err != nil {
+		logr.Debugf("Unable to list the ingresses in namespace %v, assuming %v uses https: %v", namespace, host, err)
+		return "https"
+	}
+	for _, ingress := range ingresses.Items {
+		servesHost := false
+		for _, rule := range ingress.Spec.Rules {
+			servesHost = servesHost || rule.Host == host
+		}
+		if !servesHost {
+			continue
+		}
+		for _, tls := range ingress.Spec.TLS {
+			if len(tls.Hosts) == 0 {
+				return "https"
+			}
+			for _, tlsHost := range tls.Hosts {
+				if tlsHost == host {
+					return "https"
+				}
+			}
+		}
+		return "http"
+	}
+	return "https"
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	extensionsv1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
@@ -85,6 +86,10 @@ func TestSuccessfulDeployGet(t *testing.T) {
 				Name:  "CODEWIND_AUTH_REALM",
 				Value: "codewind",
 			},
+			{
+				Name:  "CHE_INGRESS_HOST",
+				Value: "codewind-gatekeeper-WID1.nip.io",
+			},
 		},
 	}
 
@@ -115,6 +120,7 @@ func TestSuccessfulDeployGet(t *testing.T) {
 		CodewindAuthRealm: "codewind",
 		InstallDate:       timeNow.Format(time.RFC1123),
 		Version:           "0.7.0",
+		GatekeeperURL:     "https://codewind-gatekeeper-WID1.nip.io",
 	}
 
 	ExistingDeployment2 := ExistingDeployment{
@@ -167,6 +173,43 @@ func TestSuccessfulDeployGet(t *testing.T) {
 	}
 }
 
+func TestDeployGetGatekeeperScheme(t *testing.T) {
+	ingress := func(name, host string, tlsHosts ...string) *extensionsv1.Ingress {
+		ingress := &extensionsv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test1"},
+			Spec:       extensionsv1.IngressSpec{Rules: []extensionsv1.IngressRule{{Host: host}}},
+		}
+		if tlsHosts != nil {
+			ingress.Spec.TLS = []extensionsv1.IngressTLS{{Hosts: tlsHosts}}
+		}
+		return ingress
+	}
+	tests := map[string]struct {
+		ingresses  []runtime.Object
+		wantScheme string
+	}{
+		"ingress with TLS for the host": {
+			ingresses:  []runtime.Object{ingress("gatekeeper", "codewind-gatekeeper-WID1.nip.io", "codewind-gatekeeper-WID1.nip.io")},
+			wantScheme: "https",
+		},
+		"ingress without TLS": {
+			ingresses:  []runtime.Object{ingress("other", "other.nip.io", "other.nip.io"), ingress("gatekeeper", "codewind-gatekeeper-WID1.nip.io")},
+			wantScheme: "http",
+		},
+		"no ingress for the host": {
+			ingresses:  []runtime.Object{ingress("other", "other.nip.io")},
+			wantScheme: "https",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			k8s := newTestSimpleK8s()
+			k8s.clientset = fake.NewSimpleClientset(test.ingresses...)
+			assert.Equal(t, test.wantScheme, k8s.gatekeeperScheme("test1", "codewind-gatekeeper-WID1.nip.io"))
+		})
+	}
+}
+
 func checkDeployments(t *testing.T, got, want []ExistingDeployment, err *RemInstError) {
 	t.Helper()
 	if err != nil {
@@ -26,6 +26,7 @@ const (
 	errOpNotFound        = "rem_not_found"
 	errOpNoIngress       = "rem_no_ingress"
 	errOpCreateNamespace = "rem_create_namespace"
+	errOpPortForward     = "rem_port_forward"
 )
 
 const (
@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// GetWorkspaceNamespace : Finds the namespace of the Codewind deployment with the given workspace ID
+func GetWorkspaceNamespace(workspaceID string) (string, *RemInstError) {
+	existingDeployments, remErr := GetExistingDeployments("", nil)
+	if remErr != nil {
+		return "", remErr
+	}
+	for _, existingDeployment := range existingDeployments {
+		if strings.EqualFold(existingDeployment.WorkspaceID, workspaceID) {
+			return existingDeployment.Namespace, nil
+		}
+	}
+	err := errors.New(errTargetNotFound + " for workspace " + workspaceID)
+	return "", &RemInstError{errOpNotFound, err, err.Error()}
+}
+
+// PortForward : Forwards a free local port to a port of a pod. It returns the local port once the forward
+// is ready, and the forward keeps running until stopChan is closed. The returned channel is closed when the forward
+// stops, after receiving its error if it failed.
+func PortForward(namespace, podName string, podPort string, stopChan <-chan struct{}) (string, <-chan *RemInstError, *RemInstError) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return "", nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return "", nil, &RemInstError{errOpPortForward, err, err.Error()}
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(podName).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, "POST", req.URL())
+	readyChan := make(chan struct{})
+	errOut := new(bytes.Buffer)
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, []string{"0:" + podPort}, stopChan, readyChan, ioutil.Discard, errOut)
+	if err != nil {
+		return "", nil, &RemInstError{errOpPortForward, err, err.Error()}
+	}
+
+	forwardDone := make(chan error, 1)
+	go func() {
+		forwardDone <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-forwardDone:
+		if err == nil {
+			err = errors.New("port forward stopped before it was ready")
+		}
+		if errOut.Len() > 0 {
+			err = errors.New(err.Error() + ": " + strings.TrimSpace(errOut.String()))
+		}
+		return "", nil, &RemInstError{errOpPortForward, err, err.Error()}
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		if err == nil {
+			err = errors.New("port forward has no local port")
+		}
+		forwarder.Close()
+		return "", nil, &RemInstError{errOpPortForward, err, err.Error()}
+	}
+
+	doneChan := make(chan *RemInstError, 1)
+	go func() {
+		if err := <-forwardDone; err != nil {
+			doneChan <- &RemInstError{errOpPortForward, err, err.Error()}
+		}
+		close(doneChan)
+	}()
+	return strconv.Itoa(int(ports[0].Local)), doneChan, nil
+}
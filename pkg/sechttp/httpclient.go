@@ -30,6 +30,12 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 
 	logr.Tracef("Request URL: %v %v\n", originalRequest.Method, originalRequest.URL)
 
+	// Verify the server with the connection's own CA bundle or pinned certificate when it has one
+	httpClient, conErr := connections.ConnectionHTTPClient(httpClient, connection)
+	if conErr != nil {
+		return nil, &HTTPSecError{errOpTrust, conErr.Err, conErr.Desc}
+	}
+
 	if strings.ToLower(connection.ID) == "local" {
 		response, err := sendRequest(httpClient, originalRequest, "")
 		if err == nil {
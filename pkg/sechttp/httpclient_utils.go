@@ -25,6 +25,7 @@ const (
 	errOpAuthFailed   = "tx_auth"
 	errOpFailed       = "tx_failed"
 	errOpNoPassword   = "tx_nopassword"
+	errOpTrust        = "tx_trust"
 )
 
 const (
@@ -44,6 +44,12 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 	cliClient := strings.TrimSpace(strings.ToLower(c.String("client")))
 	cliPassword := strings.TrimSpace(c.String("password"))
 	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	if connectionID == "" && (cliHostname == "" || (cliRealm == "" && connectionRealm == "") || (cliClient == "" && connectionClient == "")) {
+		// the current connection provides the details not given, the local connection has none
+		if resolvedID := connections.ResolveConnectionID(""); resolvedID != "local" {
+			connectionID = resolvedID
+		}
+	}
 
 	// Check supplied context flags
 	if connectionID == "" && (cliHostname == "" || cliUsername == "" || cliRealm == "" || cliClient == "") {
@@ -67,6 +73,10 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 		hostname = connection.AuthURL
 		realm = connection.Realm
 		client = connection.ClientID
+		httpClient, ConErr = connections.ConnectionHTTPClient(httpClient, connection)
+		if ConErr != nil {
+			return nil, &SecError{errOpConConfig, ConErr.Err, ConErr.Desc}
+		}
 	}
 
 	// Use command line context flags in preference to loaded connection fields
@@ -183,7 +193,7 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 
 // SecRefreshTokens : Retrieve new tokens using the cached refresh token
 func SecRefreshTokens(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken, *SecError) {
-	conID := c.String("conid")
+	conID := connections.ResolveConnectionID(c.String("conid"))
 
 	// Read connection
 	connection, conErr := connections.GetConnectionByID(conID)
@@ -207,6 +217,11 @@ func SecRefreshTokens(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken,
 // SecRefreshAccessToken : Obtain an access token using a refresh token
 func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.Connection, refreshToken string) (*AuthToken, *SecError) {
 
+	httpClient, conErr := connections.ConnectionHTTPClient(httpClient, connection)
+	if conErr != nil {
+		return nil, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+	}
+
 	// build REST request
 	url := connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/token"
 
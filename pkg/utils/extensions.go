@@ -12,12 +12,20 @@
 package utils
 
 import (
-	"bytes"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 type (
@@ -34,80 +42,351 @@ type (
 		Name    string   `json:"name"`
 		Command string   `json:"command"`
 		Args    []string `json:"args"`
+		Timeout int      `json:"timeout,omitempty"` // seconds, no timeout if not set
 	}
 
 	// ExtensionConfig represents a project extension's config element
 	ExtensionConfig struct {
 		Style string `json:"style"`
 	}
+
+	// ExtensionMatch describes whether an extension matches a project, and why
+	ExtensionMatch struct {
+		ProjectType string `json:"projectType"`
+		Matched     bool   `json:"matched"`
+		Reason      string `json:"reason"`
+	}
+
+	// CommandOptions configures how an extension command is run
+	CommandOptions struct {
+		Env        map[string]string // added to the environment of the command
+		JSONOutput bool              // stream output as JSON events rather than plain lines
+		Output     io.Writer         // where output is streamed, os.Stderr if not set
+	}
+
+	// CommandResult represents the outcome of running an extension command
+	CommandResult struct {
+		Name     string `json:"name"`
+		ExitCode int    `json:"exitCode"`
+		Duration int64  `json:"durationMs"`
+	}
+
+	// CommandEvent is a line of output, or the exit, of an extension command streamed as JSON
+	CommandEvent struct {
+		Name     string `json:"name"`
+		Event    string `json:"event"` // "stdout", "stderr" or "exit"
+		Line     string `json:"line,omitempty"`
+		ExitCode *int   `json:"exitCode,omitempty"`
+		Duration int64  `json:"durationMs,omitempty"`
+	}
 )
 
-// Run a directive on the value
-func processDirective(value string, directive string) string {
+// Names of the extension commands run when a project is validated
+const (
+	CommandPostProjectValidate         = "postProjectValidate"
+	CommandPostProjectValidateWithType = "postProjectValidateWithType"
+)
+
+// Environment variables set for extension commands
+const (
+	EnvProjectPath    = "CW_PROJECT_PATH"
+	EnvConnectionID   = "CW_CONNECTION_ID"
+	EnvProjectType    = "CW_PROJECT_TYPE"
+	EnvProjectSubtype = "CW_PROJECT_SUBTYPE"
+)
+
+// number of lines of output included in the error when a command fails
+const commandErrorOutputLines = 10
+
+// MatchExtension returns the first extension that matches the project at the given path, along with
+// the reason each extension did or did not match. If a project type is given, extensions are matched
+// on their project type, otherwise on whether the project contains their detection file.
+func MatchExtension(extensions []Extension, projectPath string, projectType string) (*Extension, []ExtensionMatch) {
+	var matchedExtension *Extension
+	matches := []ExtensionMatch{}
+	for i, extension := range extensions {
+		match := ExtensionMatch{ProjectType: extension.ProjectType}
+		if projectType != "" {
+			match.Matched = extension.ProjectType == projectType
+			if match.Matched {
+				match.Reason = "project type matches the requested type " + projectType
+			} else {
+				match.Reason = "project type does not match the requested type " + projectType
+			}
+		} else if extension.Detection == "" {
+			match.Reason = "extension does not define a detection file"
+		} else {
+			match.Matched = PathExists(path.Join(projectPath, extension.Detection))
+			if match.Matched {
+				match.Reason = "detection file " + extension.Detection + " found in project"
+			} else {
+				match.Reason = "detection file " + extension.Detection + " not found in project"
+			}
+		}
+
+		if match.Matched {
+			if matchedExtension == nil {
+				matchedExtension = &extensions[i]
+			} else {
+				match.Reason += ", but the earlier " + matchedExtension.ProjectType + " extension is used"
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matchedExtension, matches
+}
+
+// FindCommand returns the extension's command with the given name, or nil if it has none
+func FindCommand(extension Extension, name string) *ExtensionCommand {
+	for i, command := range extension.Commands {
+		if command.Name == name {
+			return &extension.Commands[i]
+		}
+	}
+	return nil
+}
 
-	// directive for replacing extension
-	if strings.HasPrefix(directive, ".") {
+// Arguments of extension commands can substitute the values of variables, such as $type, $id or $url.
+// An argument is substituted if it has the form
+//
+//	$variable[+$variable...][,directive...]
+//
+// The values of the variables are joined together, then each directive is applied in turn:
+//
+//	.ext           replace the extension of the value with .ext, or add it if there is none
+//	basename       the last element of the path in the value
+//	dirname        the value with the last element of its path removed
+//	upper          the value in upper case
+//	lower          the value in lower case
+//	default=VALUE  use VALUE for any variable that is missing or empty
+//	join=SEP       join the values of the variables with SEP, rather than nothing
+//
+// default and join take effect when the variables are substituted, wherever they appear in the list.
+// Directive values cannot contain commas. If a variable is missing and has no default the argument is
+// passed to the command unchanged, as is any argument that does not have this form. An unknown
+// directive is an error.
+var argVariablesRegex = regexp.MustCompile(`^\$[A-Za-z0-9_]+(\+\$[A-Za-z0-9_]+)*$`)
+
+// Run a directive on the value
+func processDirective(value string, directive string) (string, error) {
+	switch {
+	case strings.HasPrefix(directive, "."):
+		// directive for replacing extension
 		ext := filepath.Ext(value)
 		if ext != "" {
 			value = strings.TrimSuffix(value, ext)
 		}
-		value += directive
+		return value + directive, nil
+	case directive == "basename":
+		return filepath.Base(value), nil
+	case directive == "dirname":
+		return filepath.Dir(value), nil
+	case directive == "upper":
+		return strings.ToUpper(value), nil
+	case directive == "lower":
+		return strings.ToLower(value), nil
+	case strings.HasPrefix(directive, "default="), strings.HasPrefix(directive, "join="):
+		// applied when the variables are substituted
+		return value, nil
 	}
-
-	return value
+	return "", fmt.Errorf("unknown directive %q", directive)
 }
 
 // Process an argument, substituting values and running directives as required
-// syntax: $variable[,directive]
-func processArg(arg string, params map[string]string) string {
+// syntax: $variable[+$variable...][,directive...]
+func processArg(arg string, params map[string]string) (string, error) {
+	// attempt to split it into the var part and the directive part
+	parts := strings.Split(arg, ",")
+	if !argVariablesRegex.MatchString(parts[0]) {
+		return arg, nil
+	}
+	variables := strings.Split(parts[0], "+")
+	directives := parts[1:]
 
-	if strings.HasPrefix(arg, "$") {
+	defaultValue, hasDefault, separator := "", false, ""
+	for _, directive := range directives {
+		if strings.HasPrefix(directive, "default=") {
+			defaultValue, hasDefault = strings.TrimPrefix(directive, "default="), true
+		} else if strings.HasPrefix(directive, "join=") {
+			separator = strings.TrimPrefix(directive, "join=")
+		}
+	}
 
-		// attempt to split it into the var part and the directive part
-		// find corresponding value in params using var part
-		parts := strings.Split(arg, ",")
-		value := params[parts[0]]
+	// find corresponding values in params using the var part
+	values := make([]string, len(variables))
+	isMissing := false
+	for i, variable := range variables {
+		values[i] = params[variable]
+		if values[i] == "" {
+			values[i] = defaultValue
+			isMissing = isMissing || !hasDefault
+		}
+	}
+	value := strings.Join(values, separator)
 
-		if value != "" {
-			// process any directives
-			if len(parts) >= 2 {
-				value = processDirective(value, parts[1])
-			}
-			return value
+	// process any directives
+	for _, directive := range directives {
+		var err error
+		value, err = processDirective(value, directive)
+		if err != nil {
+			return "", fmt.Errorf("%s in argument %q", err.Error(), arg)
 		}
 	}
 
-	return arg
+	if isMissing {
+		return arg, nil
+	}
+	return value, nil
 }
 
-// RunCommand runs a command defined by an extension
-func RunCommand(projectPath string, command ExtensionCommand, params map[string]string) error {
+// RunCommand runs a command defined by an extension, streaming its output as it runs.
+// An error is returned if the command cannot be started, exits with a non-zero status or times out.
+func RunCommand(projectPath string, command ExtensionCommand, params map[string]string, options CommandOptions) (*CommandResult, error) {
 	cwd, err := os.Executable()
 	if err != nil {
 		log.Println("There was a problem with locating the command directory")
-		return err
+		return nil, err
 	}
 	cwctlPath := filepath.Dir(cwd)
 	commandName := filepath.Base(command.Command) // prevent path traversal
 	commandBin := filepath.Join(cwctlPath, commandName)
 
-	// check for variable substitution into args
-	for i := 0; i < len(command.Args); i++ {
-		arg := command.Args[i]
-		command.Args[i] = processArg(arg, params)
+	// check for variable substitution into args, without changing the extension's own args
+	args := make([]string, len(command.Args))
+	for i, arg := range command.Args {
+		args[i], err = processArg(arg, params)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	cmd := exec.Command(commandBin, command.Args...)
+	ctx := context.Background()
+	if command.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(command.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, commandBin, args...)
 	cmd.Dir = projectPath
-	output := new(bytes.Buffer)
-	cmd.Stdout = output
-	cmd.Stderr = output
-	if err := cmd.Start(); err != nil { // after 'Start' the program is continued and script is executing in background
+	cmd.Env = os.Environ()
+	for name, value := range options.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := newCommandStream(command.Name, options)
+	startTime := time.Now()
+	if err := cmd.Start(); err != nil {
 		log.Println("There was a problem running the command:", commandName)
-		return err
+		return nil, err
 	}
-	log.Printf("Please wait while the command runs... %s", output.String())
-	cmd.Wait()
-	log.Println(output.String()) // Wait to finish execution, so we can read all output
-	return nil
+	if !options.JSONOutput {
+		log.Printf("Please wait while the command %s runs...", command.Name)
+	}
+
+	// the pipes must be read to the end before waiting for the command
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go stream.read(stdout, "stdout", &wg)
+	go stream.read(stderr, "stderr", &wg)
+	readDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(readDone)
+	}()
+	select {
+	case <-readDone:
+	case <-ctx.Done():
+		// the command has been killed, but processes it started may still hold its output open,
+		// so stop reading once Wait has closed the pipes
+	}
+	waitErr := cmd.Wait()
+	<-readDone
+
+	result := CommandResult{
+		Name:     command.Name,
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Duration: time.Since(startTime).Nanoseconds() / int64(time.Millisecond),
+	}
+	stream.exit(result)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return &result, fmt.Errorf("extension command %s timed out after %d seconds%s", command.Name, command.Timeout, stream.tail())
+	}
+	if waitErr != nil {
+		return &result, fmt.Errorf("extension command %s failed with exit code %d%s", command.Name, result.ExitCode, stream.tail())
+	}
+	return &result, nil
+}
+
+// commandStream writes the output of a running command, keeping the last lines for error reporting
+type commandStream struct {
+	name       string
+	jsonOutput bool
+	output     io.Writer
+	mutex      sync.Mutex
+	lastLines  []string
+}
+
+func newCommandStream(name string, options CommandOptions) *commandStream {
+	output := options.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	return &commandStream{name: name, jsonOutput: options.JSONOutput, output: output}
+}
+
+func (cs *commandStream) read(reader io.Reader, streamName string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		cs.writeLine(streamName, scanner.Text())
+	}
+}
+
+func (cs *commandStream) writeLine(streamName, line string) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.lastLines = append(cs.lastLines, line)
+	if len(cs.lastLines) > commandErrorOutputLines {
+		cs.lastLines = cs.lastLines[1:]
+	}
+	if cs.jsonOutput {
+		cs.writeEvent(CommandEvent{Name: cs.name, Event: streamName, Line: line})
+	} else {
+		fmt.Fprintln(cs.output, line)
+	}
+}
+
+func (cs *commandStream) exit(result CommandResult) {
+	if !cs.jsonOutput {
+		return
+	}
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	exitCode := result.ExitCode
+	cs.writeEvent(CommandEvent{Name: cs.name, Event: "exit", ExitCode: &exitCode, Duration: result.Duration})
+}
+
+func (cs *commandStream) writeEvent(event CommandEvent) {
+	eventJSON, _ := json.Marshal(event)
+	fmt.Fprintln(cs.output, string(eventJSON))
+}
+
+// tail returns the last lines of output, formatted to be appended to an error
+func (cs *commandStream) tail() string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if len(cs.lastLines) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(cs.lastLines, "\n")
 }
@@ -0,0 +1,213 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessArg(t *testing.T) {
+	params := map[string]string{
+		"$path":    "/workspace/my-project/src/app.js",
+		"$type":    "Appsody",
+		"$subtype": "nodejs-express",
+		"$empty":   "",
+	}
+
+	successTests := map[string]struct {
+		in   string
+		want string
+	}{
+		"literal argument is unchanged":                          {in: "init", want: "init"},
+		"literal argument with a comma is unchanged":             {in: "a,b", want: "a,b"},
+		"lone dollar is unchanged":                               {in: "$", want: "$"},
+		"variable is substituted":                                {in: "$type", want: "Appsody"},
+		"extension is replaced":                                  {in: "$path,.ts", want: "/workspace/my-project/src/app.ts"},
+		"extension is added":                                     {in: "$type,.yaml", want: "Appsody.yaml"},
+		"basename":                                               {in: "$path,basename", want: "app.js"},
+		"dirname":                                                {in: "$path,dirname", want: "/workspace/my-project/src"},
+		"upper case":                                             {in: "$subtype,upper", want: "NODEJS-EXPRESS"},
+		"lower case":                                             {in: "$type,lower", want: "appsody"},
+		"directives are applied in order":                        {in: "$path,dirname,basename,upper", want: "SRC"},
+		"variables are joined":                                   {in: "$type+$subtype", want: "Appsodynodejs-express"},
+		"variables are joined with a separator":                  {in: "$type+$subtype,join=/,lower", want: "appsody/nodejs-express"},
+		"default is used for a missing variable":                 {in: "$missing,default=java", want: "java"},
+		"default is used for an empty variable":                  {in: "$empty,default=java", want: "java"},
+		"default is not used for a set variable":                 {in: "$type,default=java", want: "Appsody"},
+		"default is used before other directives":                {in: "$missing,upper,default=java", want: "JAVA"},
+		"default is used for each missing joined variable":       {in: "$type+$missing,join=:,default=latest", want: "Appsody:latest"},
+		"missing variable without a default is unchanged":        {in: "$missing", want: "$missing"},
+		"missing joined variable without a default is unchanged": {in: "$type+$missing,lower", want: "$type+$missing,lower"},
+	}
+	for name, test := range successTests {
+		t.Run("success case: "+name, func(t *testing.T) {
+			got, err := processArg(test.in, params)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	failureTests := map[string]struct {
+		in      string
+		wantErr string
+	}{
+		"unknown directive":                       {in: "$type,title", wantErr: `unknown directive "title" in argument "$type,title"`},
+		"unknown directive after a valid one":     {in: "$type,lower,trim", wantErr: `unknown directive "trim" in argument "$type,lower,trim"`},
+		"empty directive":                         {in: "$type,", wantErr: `unknown directive "" in argument "$type,"`},
+		"unknown directive on a missing variable": {in: "$missing,title", wantErr: `unknown directive "title" in argument "$missing,title"`},
+	}
+	for name, test := range failureTests {
+		t.Run("fail case: "+name, func(t *testing.T) {
+			got, err := processArg(test.in, params)
+			assert.Equal(t, "", got)
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestMatchExtension(t *testing.T) {
+	os.RemoveAll("testDir")
+	defer os.RemoveAll("testDir")
+	os.MkdirAll("testDir", 0755)
+	ioutil.WriteFile(filepath.Join("testDir", "stack.yaml"), []byte{}, 0644)
+
+	validateCommand := ExtensionCommand{Name: CommandPostProjectValidate, Command: "appsody"}
+	appsody := Extension{ProjectType: "appsodyExtension", Detection: "stack.yaml", Commands: []ExtensionCommand{validateCommand}}
+	odo := Extension{ProjectType: "odo", Detection: ".odo/config.yaml"}
+	other := Extension{ProjectType: "other", Detection: "stack.yaml"}
+	noDetection := Extension{ProjectType: "noDetection"}
+	extensions := []Extension{noDetection, odo, appsody, other}
+
+	t.Run("success case: matched on detection file", func(t *testing.T) {
+		got, matches := MatchExtension(extensions, "testDir", "")
+		assert.Equal(t, &extensions[2], got)
+		assert.Equal(t, []ExtensionMatch{
+			{"noDetection", false, "extension does not define a detection file"},
+			{"odo", false, "detection file .odo/config.yaml not found in project"},
+			{"appsodyExtension", true, "detection file stack.yaml found in project"},
+			{"other", true, "detection file stack.yaml found in project, but the earlier appsodyExtension extension is used"},
+		}, matches)
+	})
+
+	t.Run("success case: matched on project type", func(t *testing.T) {
+		got, matches := MatchExtension(extensions, "testDir", "odo")
+		assert.Equal(t, &extensions[1], got)
+		assert.Equal(t, ExtensionMatch{"odo", true, "project type matches the requested type odo"}, matches[1])
+		assert.Equal(t, ExtensionMatch{"appsodyExtension", false, "project type does not match the requested type odo"}, matches[2])
+	})
+
+	t.Run("fail case: no extension matches", func(t *testing.T) {
+		got, matches := MatchExtension(extensions, "testDir", "docker")
+		assert.Nil(t, got)
+		assert.Len(t, matches, 4)
+	})
+
+	t.Run("success case: command is found by name", func(t *testing.T) {
+		assert.Equal(t, &validateCommand, FindCommand(appsody, CommandPostProjectValidate))
+		assert.Nil(t, FindCommand(appsody, CommandPostProjectValidateWithType))
+	})
+}
+
+// writeTestCommand writes a shell script next to the test binary, where RunCommand looks for extension commands
+func writeTestCommand(t *testing.T, name, script string) {
+	executable, err := os.Executable()
+	require.Nil(t, err)
+	commandPath := filepath.Join(filepath.Dir(executable), name)
+	err = ioutil.WriteFile(commandPath, []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	require.Nil(t, err)
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("extension command tests use shell scripts")
+	}
+
+	writeTestCommand(t, "cw-test-echo", `echo "$1"; echo "$CW_PROJECT_TYPE" >&2`)
+	writeTestCommand(t, "cw-test-fail", `echo "something went wrong"; exit 3`)
+	writeTestCommand(t, "cw-test-sleep", `sleep 5`)
+
+	t.Run("success case: output is streamed and environment is set", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-echo", Args: []string{"$type"}}
+		options := CommandOptions{Env: map[string]string{EnvProjectType: "appsodyExtension"}, Output: output}
+
+		result, err := RunCommand("", command, map[string]string{"$type": "java"}, options)
+		require.Nil(t, err)
+		assert.Equal(t, "postProjectValidate", result.Name)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.Contains(t, output.String(), "java\n")
+		assert.Contains(t, output.String(), "appsodyExtension\n")
+		assert.Equal(t, []string{"$type"}, command.Args, "the extension's args should not be changed")
+	})
+
+	t.Run("success case: output is streamed as JSON events", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-echo", Args: []string{"hello"}}
+
+		_, err := RunCommand("", command, nil, CommandOptions{JSONOutput: true, Output: output})
+		require.Nil(t, err)
+		events := []CommandEvent{}
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var event CommandEvent
+			require.Nil(t, json.Unmarshal([]byte(line), &event))
+			events = append(events, event)
+		}
+		assert.Contains(t, events, CommandEvent{Name: "postProjectValidate", Event: "stdout", Line: "hello"})
+		lastEvent := events[len(events)-1]
+		assert.Equal(t, "exit", lastEvent.Event)
+		assert.Equal(t, 0, *lastEvent.ExitCode)
+	})
+
+	t.Run("fail case: non-zero exit status is an error", func(t *testing.T) {
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-fail"}
+
+		result, err := RunCommand("", command, nil, CommandOptions{Output: ioutil.Discard})
+		assert.Equal(t, 3, result.ExitCode)
+		assert.EqualError(t, err, "extension command postProjectValidate failed with exit code 3: something went wrong")
+	})
+
+	t.Run("fail case: command is stopped when it times out", func(t *testing.T) {
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-sleep", Timeout: 1}
+
+		result, err := RunCommand("", command, nil, CommandOptions{Output: ioutil.Discard})
+		assert.EqualError(t, err, "extension command postProjectValidate timed out after 1 seconds")
+		assert.True(t, result.Duration < 5000)
+	})
+
+	t.Run("fail case: unknown directive is reported before the command runs", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-echo", Args: []string{"$type,title"}}
+
+		result, err := RunCommand("", command, map[string]string{"$type": "java"}, CommandOptions{Output: output})
+		assert.Nil(t, result)
+		assert.EqualError(t, err, `unknown directive "title" in argument "$type,title"`)
+		assert.Equal(t, "", output.String())
+	})
+
+	t.Run("fail case: missing command", func(t *testing.T) {
+		command := ExtensionCommand{Name: "postProjectValidate", Command: "cw-test-does-not-exist"}
+
+		result, err := RunCommand("", command, nil, CommandOptions{Output: ioutil.Discard})
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+}
@@ -255,6 +255,34 @@ func CopyFile(sourceFilePath, targetFilePath string) error {
 	return err
 }
 
+// WriteFileAtomically - writes a file to a temporary file in the same directory, flushes it to disk and
+// renames it over the file
+func WriteFileAtomically(filename string, body []byte) error {
+	tempFile, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	_, err = tempFile.Write(body)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, filename)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+	}
+	return err
+}
+
 //Zip - creates a zip file in the target directory and populates it with the contents of that directory
 func Zip(zipFileName, targetDirectory string) error {
 	newZipFile, zipCreateErr := os.Create(filepath.Join(targetDirectory, zipFileName))
@@ -131,3 +131,21 @@ func TestReplaceInFiles(t *testing.T) {
 		assert.Equal(t, wantFileContent, fileContent)
 	})
 }
+
+func TestWriteFileAtomically(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "config.json")
+	ioutil.WriteFile(filename, []byte("old"), 0600)
+
+	err = WriteFileAtomically(filename, []byte("new"))
+	assert.Nil(t, err)
+	contents, _ := ioutil.ReadFile(filename)
+	assert.Equal(t, "new", string(contents))
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+	assert.Equal(t, os.FileMode(0644), files[0].Mode().Perm())
+}
@@ -67,7 +67,7 @@ func onRepositoryAdd(extensions []Extension, repo TemplateRepo) {
 			params := make(map[string]string)
 			params["$id"] = repo.ID
 			params["$url"] = repo.URL
-			RunCommand("", *cmdPtr, params)
+			RunCommand("", *cmdPtr, params, CommandOptions{})
 		}
 	}
 }
@@ -83,7 +83,7 @@ func onRepositoryRemove(extensions []Extension, repo TemplateRepo) {
 		if cmdPtr != nil {
 			params := make(map[string]string)
 			params["$id"] = repo.ID
-			RunCommand("", *cmdPtr, params)
+			RunCommand("", *cmdPtr, params, CommandOptions{})
 		}
 	}
 }